@@ -0,0 +1,92 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// buildAddressTranslator returns a translator mapping the private ip:port addresses advertised by nodes to public ones
+func buildAddressTranslator(ctx context.Context, raw map[string]interface{}) (gocql.AddressTranslator, error) {
+	translations := make(map[string]string, len(raw))
+
+	for private, value := range raw {
+		public := value.(string)
+
+		privateIP, privatePort, err := parseIPAndPort(private)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, _, err := parseIPAndPort(public); err != nil {
+			return nil, err
+		}
+
+		translations[net.JoinHostPort(privateIP.String(), strconv.Itoa(privatePort))] = public
+	}
+
+	return gocql.AddressTranslatorFunc(func(addr net.IP, port int) (net.IP, int) {
+		public, ok := translations[net.JoinHostPort(addr.String(), strconv.Itoa(port))]
+
+		if !ok {
+			return addr, port
+		}
+
+		publicIP, publicPort, _ := parseIPAndPort(public)
+
+		tflog.Trace(ctx, "Translating address", map[string]interface{}{
+			"address":            net.JoinHostPort(addr.String(), strconv.Itoa(port)),
+			"translated_address": public,
+		})
+
+		return publicIP, publicPort
+	}), nil
+}
+
+func parseIPAndPort(address string) (net.IP, int, error) {
+	host, rawPort, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s is not a valid ip:port address: %v", address, err)
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil {
+		return nil, 0, fmt.Errorf("%s is not a valid ip:port address: %s is not an IP", address, host)
+	}
+
+	port, err := strconv.Atoi(rawPort)
+
+	if err != nil || port < 1 || port > 65535 {
+		return nil, 0, fmt.Errorf("%s is not a valid ip:port address: invalid port %s", address, rawPort)
+	}
+
+	return ip, port, nil
+}
+
+func validateAddressTranslation(i interface{}, path cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for private, value := range i.(map[string]interface{}) {
+		for _, address := range []string{private, value.(string)} {
+			if _, _, err := parseIPAndPort(address); err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       "Invalid address translation",
+					Detail:        err.Error(),
+					AttributePath: path,
+				})
+			}
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,189 @@
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	operationCreate = "create"
+	operationRead   = "read"
+	operationUpdate = "update"
+	operationDelete = "delete"
+
+	auditResultSuccess = "success"
+	auditResultError   = "error"
+)
+
+var (
+	passwordRegex = regexp.MustCompile(`(?i)(PASSWORD\s*=\s*)'(?:[^']|'')*'`)
+)
+
+type auditContextKey struct{}
+
+type auditWarningsContextKey struct{}
+
+// auditResource identifies the resource and operation a statement is executed for
+type auditResource struct {
+	Address   string
+	Operation string
+}
+
+// auditRecord is a line of the audit log
+type auditRecord struct {
+	Timestamp   string  `json:"timestamp"`
+	Resource    string  `json:"resource,omitempty"`
+	Operation   string  `json:"operation,omitempty"`
+	Statement   string  `json:"statement"`
+	Coordinator string  `json:"coordinator,omitempty"`
+	LatencyMs   float64 `json:"latency_ms"`
+	Result      string  `json:"result"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// auditLog appends a JSON line per executed statement to a file, it implements gocql.QueryObserver
+type auditLog struct {
+	lock    sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// auditWarnings collects the audit log failures of a resource operation
+type auditWarnings struct {
+	lock   sync.Mutex
+	errors []string
+}
+
+// withAuditWarnings wraps a resource operation to report the audit log failures of its statements as warnings,
+// an audit failure must not fail the operation
+func withAuditWarnings(operation func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		warnings := &auditWarnings{}
+
+		diags := operation(context.WithValue(ctx, auditWarningsContextKey{}, warnings), d, meta)
+
+		return append(diags, warnings.diagnostics()...)
+	}
+}
+
+func (w *auditWarnings) add(err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, message := range w.errors {
+		if message == err.Error() {
+			return
+		}
+	}
+
+	w.errors = append(w.errors, err.Error())
+}
+
+func (w *auditWarnings) diagnostics() diag.Diagnostics {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var diags diag.Diagnostics
+
+	for _, message := range w.errors {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to write the audit log",
+			Detail:   message,
+		})
+	}
+
+	return diags
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+
+	return &auditLog{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (a *auditLog) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	record := auditRecord{
+		Timestamp: q.Start.UTC().Format(time.RFC3339Nano),
+		Statement: redactStatement(q.Statement),
+		LatencyMs: float64(q.End.Sub(q.Start).Microseconds()) / 1000,
+		Result:    auditResultSuccess,
+	}
+
+	if resource, ok := ctx.Value(auditContextKey{}).(auditResource); ok {
+		record.Resource = resource.Address
+		record.Operation = resource.Operation
+	}
+
+	if q.Host != nil {
+		record.Coordinator = hostAddress(q.Host)
+	}
+
+	if q.Err != nil {
+		record.Result = auditResultError
+		record.Error = q.Err.Error()
+	}
+
+	a.write(ctx, record)
+}
+
+func (a *auditLog) write(ctx context.Context, record auditRecord) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		return
+	}
+
+	// an audit failure must not fail the apply, the error surfaces in the provider log and as a warning of the operation
+	if err := a.encoder.Encode(record); err != nil {
+		tflog.Warn(ctx, "Unable to write the audit log", map[string]interface{}{
+			"error": err.Error(),
+		})
+
+		if warnings, ok := ctx.Value(auditWarningsContextKey{}).(*auditWarnings); ok {
+			warnings.add(err)
+		}
+	}
+}
+
+func (a *auditLog) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+
+	err := a.file.Close()
+	a.file = nil
+
+	return err
+}
+
+// redactStatement hides the passwords of role statements
+func redactStatement(statement string) string {
+	return passwordRegex.ReplaceAllString(statement, "${1}'***'")
+}
+
+// query is the single place statements of the resources are built, the context attributes the statement
+// to its resource in the logs and the audit log written by the query observer of the client
+func (c *Client) query(ctx context.Context, session *gocql.Session, statement string, values ...interface{}) *gocql.Query {
+	ctx = tflog.SetField(ctx, logFieldStatementKind, statementKind(statement))
+
+	return applyConsistency(ctx, session.Query(statement, values...).WithContext(ctx))
+}
@@ -0,0 +1,145 @@
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAudit_redactStatement(t *testing.T) {
+	for statement, expected := range map[string]string{
+		`CREATE ROLE 'app' WITH PASSWORD = 'secret' AND LOGIN = true`: `CREATE ROLE 'app' WITH PASSWORD = '***' AND LOGIN = true`,
+		`alter role 'app' with password='it''s secret'`:               `alter role 'app' with password='***'`,
+		`DROP ROLE 'app'`: `DROP ROLE 'app'`,
+	} {
+		if redacted := redactStatement(statement); redacted != expected {
+			t.Fatalf("expected %s, got %s", expected, redacted)
+		}
+	}
+}
+
+func TestAudit_observeQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	audit, err := openAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := withResource(context.Background(), "cassandra_role", "app", operationCreate)
+
+	audit.ObserveQuery(ctx, gocql.ObservedQuery{
+		Statement: `CREATE ROLE 'app' WITH PASSWORD = 'secret'`,
+		Start:     start,
+		End:       start.Add(1500 * time.Microsecond),
+	})
+	audit.ObserveQuery(context.Background(), gocql.ObservedQuery{
+		Statement: `DROP ROLE 'app'`,
+		Start:     start,
+		End:       start,
+		Err:       errors.New("role app doesn't exist"),
+	})
+	audit.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var record auditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := auditRecord{
+		Timestamp: "2022-03-01T12:00:00Z",
+		Resource:  "cassandra_role.app",
+		Operation: operationCreate,
+		Statement: `CREATE ROLE 'app' WITH PASSWORD = '***'`,
+		LatencyMs: 1.5,
+		Result:    auditResultSuccess,
+	}
+	if record != expected {
+		t.Fatalf("expected %+v, got %+v", expected, record)
+	}
+
+	if strings.Contains(string(content), "secret") {
+		t.Fatal("expected password to be redacted")
+	}
+
+	record = auditRecord{}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Result != auditResultError || record.Error != "role app doesn't exist" || record.Resource != "" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestAudit_writeFailure(t *testing.T) {
+	audit, err := openAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+
+	// writes fail once the file is closed
+	audit.file.Close()
+
+	operation := withAuditWarnings(func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		for _, statement := range []string{`DROP ROLE 'app'`, `DROP ROLE 'other'`} {
+			audit.ObserveQuery(ctx, gocql.ObservedQuery{Statement: statement})
+		}
+
+		return nil
+	})
+
+	diags := operation(context.Background(), nil, nil)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "Unable to write the audit log" {
+		t.Fatalf("unexpected diagnostics %+v", diags)
+	}
+}
+
+func TestAudit_configure(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":           "asdf",
+		"audit_log_path": filepath.Join(t.TempDir(), "missing", "audit.log"),
+	})
+	diags := Provider().Configure(context.Background(), rc)
+	if !diags.HasError() || diags[0].Summary != "Unable to open audit log" {
+		t.Fatalf("unexpected diagnostics %+v", diags)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	rc = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":           "asdf",
+		"audit_log_path": path,
+	})
+	p := Provider()
+	diags = p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	if p.Meta().(*Client).audit == nil {
+		t.Fatal("expected audit log to be opened")
+	}
+}
@@ -0,0 +1,180 @@
+package cassandra
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	bundleConfigFile     = "config.json"
+	bundleDefaultCACert  = "ca.crt"
+	bundleDefaultCert    = "cert"
+	bundleDefaultKey     = "key"
+	bundleDefaultCQLPort = 9042
+)
+
+// secureConnectBundle holds the connection settings and certificates of a secure connect bundle
+type secureConnectBundle struct {
+	Host     string `json:"host"`
+	CQLPort  int    `json:"cql_port"`
+	Keyspace string `json:"keyspace"`
+	LocalDC  string `json:"localDC"`
+
+	CACertLocation string `json:"caCertLocation"`
+	CertLocation   string `json:"certLocation"`
+	KeyLocation    string `json:"keyLocation"`
+
+	caCert []byte
+	cert   []byte
+	key    []byte
+}
+
+// loadSecureConnectBundle reads a secure connect bundle from a file path or from base64 encoded zip content
+func loadSecureConnectBundle(value string) (*secureConnectBundle, error) {
+	var content []byte
+
+	if _, err := os.Stat(value); err == nil {
+		content, err = ioutil.ReadFile(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot read secure connect bundle: %w", err)
+		}
+	} else {
+		content, err = base64.StdEncoding.DecodeString(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("secure connect bundle is neither an existing file nor base64 content")
+		}
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open secure connect bundle: %w", err)
+	}
+
+	files := make(map[string][]byte)
+
+	for _, file := range archive.File {
+		reader, err := file.Open()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot open %s in secure connect bundle: %w", file.Name, err)
+		}
+
+		data, err := ioutil.ReadAll(reader)
+		reader.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s in secure connect bundle: %w", file.Name, err)
+		}
+
+		files[path.Clean(file.Name)] = data
+	}
+
+	config, ok := files[bundleConfigFile]
+
+	if !ok {
+		return nil, fmt.Errorf("secure connect bundle does not contain %s", bundleConfigFile)
+	}
+
+	bundle := &secureConnectBundle{
+		CQLPort:        bundleDefaultCQLPort,
+		CACertLocation: bundleDefaultCACert,
+		CertLocation:   bundleDefaultCert,
+		KeyLocation:    bundleDefaultKey,
+	}
+
+	if err := json.Unmarshal(config, bundle); err != nil {
+		return nil, fmt.Errorf("cannot parse %s in secure connect bundle: %w", bundleConfigFile, err)
+	}
+
+	if bundle.Host == "" {
+		return nil, fmt.Errorf("%s in secure connect bundle does not contain a host", bundleConfigFile)
+	}
+
+	for _, entry := range []struct {
+		location string
+		target   *[]byte
+	}{
+		{bundle.CACertLocation, &bundle.caCert},
+		{bundle.CertLocation, &bundle.cert},
+		{bundle.KeyLocation, &bundle.key},
+	} {
+		data, ok := files[path.Clean(entry.location)]
+
+		if !ok {
+			return nil, fmt.Errorf("secure connect bundle does not contain %s", entry.location)
+		}
+
+		*entry.target = data
+	}
+
+	return bundle, nil
+}
+
+// tlsConfig returns the TLS configuration authenticating with the bundle certificates, the bundle host is used for SNI
+func (b *secureConnectBundle) tlsConfig(minVersion uint16) (*tls.Config, error) {
+	caPool := x509.NewCertPool()
+
+	if !caPool.AppendCertsFromPEM(b.caCert) {
+		return nil, fmt.Errorf("invalid CA certificate in secure connect bundle")
+	}
+
+	certificate, err := tls.X509KeyPair(b.cert, b.key)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate in secure connect bundle: %w", err)
+	}
+
+	return &tls.Config{
+		MinVersion:   minVersion,
+		RootCAs:      caPool,
+		Certificates: []tls.Certificate{certificate},
+		ServerName:   b.Host,
+	}, nil
+}
+
+// applySecureConnectBundle sets the hosts, port and TLS configuration of the cluster from a secure connect bundle,
+// the bundle is returned for its local datacenter and keyspace
+func applySecureConnectBundle(ctx context.Context, cluster *gocql.ClusterConfig, value string, minTLSVersion uint16) (*secureConnectBundle, error) {
+	bundle, err := loadSecureConnectBundle(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := bundle.tlsConfig(minTLSVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Debug(ctx, "Using secure connect bundle", map[string]interface{}{
+		"host":     bundle.Host,
+		"port":     bundle.CQLPort,
+		"local_dc": bundle.LocalDC,
+		"keyspace": bundle.Keyspace,
+	})
+
+	cluster.Hosts = []string{bundle.Host}
+	cluster.Port = bundle.CQLPort
+	cluster.SslOpts = &gocql.SslOptions{
+		Config:                 tlsConfig,
+		EnableHostVerification: true,
+	}
+
+	return bundle, nil
+}
@@ -0,0 +1,173 @@
+package cassandra
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestSecureConnectBundle_tlsListener(t *testing.T) {
+	serverCert, serverKey := testGenerateCertificate(t)
+	clientCert, clientKey := testGenerateCertificate(t)
+
+	certificate, err := tls.X509KeyPair([]byte(serverCert), []byte(serverKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(clientCert))
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.(*tls.Conn).Handshake()
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	bundle := testSecureConnectBundle(t, fmt.Sprintf(`{"host": "localhost", "cql_port": %d}`, port), serverCert, clientCert, clientKey)
+	bundleFile := filepath.Join(t.TempDir(), "secure-connect.zip")
+
+	if err := ioutil.WriteFile(bundleFile, bundle, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"secure_connect_bundle": bundleFile,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	cluster := p.Meta().(*Client).Cluster()
+	if len(cluster.Hosts) != 1 || cluster.Hosts[0] != "localhost" || cluster.Port != port {
+		t.Fatalf("unexpected hosts %v and port %d", cluster.Hosts, cluster.Port)
+	}
+
+	conn, err := tls.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", cluster.Port), cluster.SslOpts.Config)
+	if err != nil {
+		t.Fatalf("TLS handshake failed: %v", err)
+	}
+	conn.Close()
+}
+
+func TestSecureConnectBundle_base64(t *testing.T) {
+	cert, key := testGenerateCertificate(t)
+	bundle := testSecureConnectBundle(t, `{"host": "db.example.com", "cql_port": 29042, "localDC": "dc1"}`, cert, cert, key)
+
+	loaded, err := loadSecureConnectBundle(base64.StdEncoding.EncodeToString(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Host != "db.example.com" || loaded.CQLPort != 29042 || loaded.LocalDC != "dc1" {
+		t.Fatalf("unexpected bundle %+v", loaded)
+	}
+
+	if _, err := loadSecureConnectBundle("not a bundle"); err == nil {
+		t.Fatal("expected invalid bundle to fail")
+	}
+}
+
+func TestSecureConnectBundle_conflictsWithHost(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":                  "asdf",
+		"secure_connect_bundle": "/tmp/secure-connect.zip",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected host and secure_connect_bundle to conflict")
+	}
+}
+
+func TestSecureConnectBundle_configure(t *testing.T) {
+	cert, key := testGenerateCertificate(t)
+	bundle := testSecureConnectBundle(t, `{"host": "127.0.0.1", "cql_port": 29042, "keyspace": "app", "localDC": "dc1"}`, cert, cert, key)
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"secure_connect_bundle": base64.StdEncoding.EncodeToString(bundle),
+		"host_filter":           true,
+		"render_only_dir":       t.TempDir(),
+	})
+	p := Provider()
+	if diags := p.Configure(context.Background(), rc); diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	cluster := p.Meta().(*Client).Cluster()
+
+	if cluster.Keyspace != "app" {
+		t.Fatalf("expected the keyspace of the bundle, got %q", cluster.Keyspace)
+	}
+
+	if cluster.HostFilter == nil {
+		t.Fatal("expected a host filter")
+	}
+
+	if !cluster.HostFilter.Accept((&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("127.0.0.1"))) {
+		t.Fatal("expected the host filter to accept the host of the bundle")
+	}
+
+	if cluster.HostFilter.Accept((&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("127.0.0.2"))) {
+		t.Fatal("expected the host filter to reject other hosts")
+	}
+}
+
+func testSecureConnectBundle(t *testing.T, config string, caCert string, cert string, key string) []byte {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+
+	for name, content := range map[string]string{
+		"config.json": config,
+		"ca.crt":      caCert,
+		"cert":        cert,
+		"key":         key,
+	} {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
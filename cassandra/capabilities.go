@@ -0,0 +1,172 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	featureRoles                = "roles"
+	featureFunctionPermissions  = "function permissions"
+	featureMbeanPermissions     = "mbean permissions"
+	featureTransientReplication = "transient replication"
+)
+
+var (
+	// featureMinimumVersions is the first Cassandra release supporting each feature
+	featureMinimumVersions = map[string]serverVersion{
+		featureRoles:                {2, 2, 0},
+		featureFunctionPermissions:  {2, 2, 0},
+		featureMbeanPermissions:     {3, 6, 0},
+		featureTransientReplication: {4, 0, 0},
+	}
+
+	resourceTypeToFeature = map[string]string{
+		resourceAllFunctions:           featureFunctionPermissions,
+		resourceAllFunctionsInKeyspace: featureFunctionPermissions,
+		resourceFunction:               featureFunctionPermissions,
+		resourceAllRoles:               featureRoles,
+		resourceRole:                   featureRoles,
+		resourceRoles:                  featureRoles,
+		resourceMbean:                  featureMbeanPermissions,
+		resourceMbeans:                 featureMbeanPermissions,
+		resourceAllMbeans:              featureMbeanPermissions,
+	}
+)
+
+// serverVersion is the numeric part of a release_version such as 3.11.10 or 4.1-SNAPSHOT
+type serverVersion struct {
+	major, minor, patch int
+}
+
+func parseServerVersion(releaseVersion string) (serverVersion, error) {
+	var version serverVersion
+
+	numeric := releaseVersion
+
+	if index := strings.IndexAny(numeric, "-+"); index >= 0 {
+		numeric = numeric[:index]
+	}
+
+	parts := strings.Split(numeric, ".")
+
+	if len(parts) < 2 || len(parts) > 4 {
+		return version, fmt.Errorf("cannot parse release_version %s", releaseVersion)
+	}
+
+	numbers := make([]int, 3)
+
+	for i := 0; i < len(parts) && i < 3; i++ {
+		number, err := strconv.Atoi(parts[i])
+
+		if err != nil {
+			return version, fmt.Errorf("cannot parse release_version %s", releaseVersion)
+		}
+
+		numbers[i] = number
+	}
+
+	return serverVersion{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+func (v serverVersion) atLeast(other serverVersion) bool {
+	if v.major != other.major {
+		return v.major > other.major
+	}
+
+	if v.minor != other.minor {
+		return v.minor > other.minor
+	}
+
+	return v.patch >= other.patch
+}
+
+func (v serverVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// capabilities tells which features the cluster supports, based on its release_version
+type capabilities struct {
+	releaseVersion string
+	version        serverVersion
+}
+
+func newCapabilities(releaseVersion string) (*capabilities, error) {
+	version, err := parseServerVersion(releaseVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &capabilities{releaseVersion: releaseVersion, version: version}, nil
+}
+
+// supports returns whether the cluster supports the feature
+func (c *capabilities) supports(feature string) bool {
+	return c.version.atLeast(featureMinimumVersions[feature])
+}
+
+// require returns an error naming the minimum version when the cluster does not support the feature
+func (c *capabilities) require(feature string) error {
+	if c.supports(feature) {
+		return nil
+	}
+
+	return fmt.Errorf("%s requires Cassandra %s or later, the cluster runs %s", feature, featureMinimumVersions[feature], c.releaseVersion)
+}
+
+// Capabilities returns the capabilities of the cluster, release_version is queried once per provider
+func (c *Client) Capabilities(ctx context.Context) (*capabilities, error) {
+	c.capabilitiesLock.Lock()
+	defer c.capabilitiesLock.Unlock()
+
+	if c.capabilities != nil {
+		return c.capabilities, nil
+	}
+
+	session, err := c.Session(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseVersion string
+
+	if err := session.Query(`SELECT release_version FROM system.local`).WithContext(ctx).Scan(&releaseVersion); err != nil {
+		return nil, fmt.Errorf("cannot read release_version from system.local: %w", err)
+	}
+
+	capabilities, err := newCapabilities(releaseVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Debug(ctx, "Read cluster release_version", map[string]interface{}{
+		"release_version": releaseVersion,
+	})
+
+	c.capabilities = capabilities
+
+	return capabilities, nil
+}
+
+// requireFeature returns an error naming the minimum version when the cluster does not support the feature
+func (c *Client) requireFeature(ctx context.Context, feature string) error {
+	if c.offline() {
+		// the cluster is not contacted, the version is checked by the DBA running the rendered statements or at apply
+		return nil
+	}
+
+	capabilities, err := c.Capabilities(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return capabilities.require(feature)
+}
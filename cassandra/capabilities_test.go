@@ -0,0 +1,74 @@
+package cassandra
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+)
+
+func TestCapabilities_parseServerVersion(t *testing.T) {
+	for releaseVersion, expected := range map[string]serverVersion{
+		"2.2.19":       {2, 2, 19},
+		"3.11.10":      {3, 11, 10},
+		"4.0":          {4, 0, 0},
+		"4.1-SNAPSHOT": {4, 1, 0},
+		"5.0-beta1":    {5, 0, 0},
+		"3.0.8.1":      {3, 0, 8},
+	} {
+		version, err := parseServerVersion(releaseVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, releaseVersion, version)
+		}
+	}
+
+	for _, releaseVersion := range []string{"", "4", "four.0"} {
+		if _, err := parseServerVersion(releaseVersion); err == nil {
+			t.Fatalf("expected %q to fail parsing", releaseVersion)
+		}
+	}
+}
+
+func TestCapabilities_require(t *testing.T) {
+	capabilities, err := newCapabilities("2.2.19")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := capabilities.require(featureRoles); err != nil {
+		t.Fatal(err)
+	}
+
+	err = capabilities.require(resourceTypeToFeature[resourceAllMbeans])
+	if err == nil || !strings.Contains(err.Error(), "mbean permissions requires Cassandra 3.6.0 or later, the cluster runs 2.2.19") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	capabilities, err = newCapabilities("4.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for feature := range featureMinimumVersions {
+		if !capabilities.supports(feature) {
+			t.Fatalf("expected 4.0.1 to support %s", feature)
+		}
+	}
+}
+
+func TestCapabilities_cachedPerClient(t *testing.T) {
+	client := NewClient(gocql.NewCluster())
+	defer CloseClients()
+
+	client.capabilities, _ = newCapabilities("3.0.24")
+
+	// the cached capabilities are used without connecting to the cluster
+	if err := client.requireFeature(context.Background(), featureTransientReplication); err == nil || !strings.Contains(err.Error(), "requires Cassandra 4.0.0 or later") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
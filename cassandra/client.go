@@ -0,0 +1,270 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+var (
+	openClientsLock sync.Mutex
+	openClients     []*Client
+)
+
+// Client holds the cluster configuration and a single session shared by all resources
+type Client struct {
+	cluster *gocql.ClusterConfig
+
+	lock    sync.Mutex
+	session *gocql.Session
+
+	connectErrorLock sync.Mutex
+	connectError     error
+
+	protocolVersionLock sync.Mutex
+	protocolVersion     int
+
+	flavorLock sync.Mutex
+	flavor     string
+
+	capabilitiesLock sync.Mutex
+	capabilities     *capabilities
+
+	audit    *auditLog
+	renderer *renderer
+
+	// unknownAttributes are the provider attributes unknown during plan, the client never connects when set
+	unknownAttributes []string
+
+	// logContext carries the provider logger for callbacks of the driver which have no context
+	logContext context.Context
+
+	// ddlLock serializes schema changes, minDDLInterval rate limits them
+	ddlLock        chan struct{}
+	minDDLInterval time.Duration
+	lastDDL        time.Time
+}
+
+// NewClient returns a Client for the given cluster configuration, the session is created on first use
+func NewClient(cluster *gocql.ClusterConfig) *Client {
+	client := &Client{cluster: cluster, flavor: flavorCassandra, ddlLock: make(chan struct{}, 1), logContext: context.Background()}
+	cluster.ConnectObserver = client
+	cluster.QueryObserver = client
+	cluster.FrameHeaderObserver = client
+
+	openClientsLock.Lock()
+	openClients = append(openClients, client)
+	openClientsLock.Unlock()
+
+	return client
+}
+
+// Cluster returns the cluster configuration used to create the session
+func (c *Client) Cluster() *gocql.ClusterConfig {
+	return c.cluster
+}
+
+// ObserveConnect traces connections and records the last connection error, gocql only reports a generic error when no connection could be made
+func (c *Client) ObserveConnect(connect gocql.ObservedConnect) {
+	fields := map[string]interface{}{
+		"host":       hostAddress(connect.Host),
+		"latency_ms": float64(connect.End.Sub(connect.Start).Microseconds()) / 1000,
+	}
+
+	if connect.Err == nil {
+		tflog.Trace(c.logContext, "Connected to host", fields)
+		return
+	}
+
+	fields["error"] = connect.Err.Error()
+
+	tflog.Trace(c.logContext, "Connecting to host failed", fields)
+
+	c.connectErrorLock.Lock()
+	c.connectError = connect.Err
+	c.connectErrorLock.Unlock()
+}
+
+// lastConnectError returns the last error observed while connecting to a host
+func (c *Client) lastConnectError() error {
+	c.connectErrorLock.Lock()
+	defer c.connectErrorLock.Unlock()
+
+	return c.connectError
+}
+
+// Session returns the shared session, creating it when it does not exist yet, the creation stops when the context is cancelled
+func (c *Client) Session(ctx context.Context) (*gocql.Session, error) {
+	if c.deferred() {
+		return nil, c.deferredError()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.session != nil && !c.session.Closed() {
+		return c.session, nil
+	}
+
+	start := time.Now()
+	session, err := createSession(ctx, c.cluster)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		tflog.Error(ctx, "Creating a session failed", map[string]interface{}{
+			"duration": elapsed.String(),
+			"error":    err.Error(),
+		})
+		return nil, fmt.Errorf("cannot create session with protocol version %s: %w", protocolVersionName(c.cluster.ProtoVersion), err)
+	}
+
+	if c.cluster.ProtoVersion == 0 {
+		// negotiated by gocql, stepping down from the highest version it supports, and kept for the next sessions
+		c.cluster.ProtoVersion = c.observedProtocolVersion()
+	}
+
+	tflog.Debug(ctx, "Created a session", map[string]interface{}{
+		"protocol_version": c.cluster.ProtoVersion,
+		"duration":         elapsed.String(),
+	})
+
+	c.session = session
+
+	return session, nil
+}
+
+// createSession creates a session of the cluster, gocql does not take a context so a cancelled creation
+// returns early and the session is closed once the driver is done
+func createSession(ctx context.Context, cluster *gocql.ClusterConfig) (*gocql.Session, error) {
+	type result struct {
+		session *gocql.Session
+		err     error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		session, err := cluster.CreateSession()
+		done <- result{session, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.session, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.session != nil {
+				r.session.Close()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// checkDatacenter returns an error when no host in system.local or system.peers belongs to the datacenter
+func (c *Client) checkDatacenter(ctx context.Context, name string) error {
+	session, err := c.Session(ctx)
+
+	if err != nil {
+		return c.connectionError(err)
+	}
+
+	var (
+		datacenter  string
+		datacenters []string
+	)
+
+	for _, query := range []string{`SELECT data_center FROM system.local`, `SELECT data_center FROM system.peers`} {
+		iter := session.Query(query).WithContext(ctx).Iter()
+
+		for iter.Scan(&datacenter) {
+			if datacenter == name {
+				iter.Close()
+				return nil
+			}
+
+			datacenters = append(datacenters, datacenter)
+		}
+
+		if err := iter.Close(); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("datacenter %s not found, known datacenters are %s", name, strings.Join(datacenters, ", "))
+}
+
+// schemaAgreementAwaiter waits until all hosts report the same schema version, it is implemented by gocql.Session
+type schemaAgreementAwaiter interface {
+	AwaitSchemaAgreement(ctx context.Context) error
+}
+
+// awaitSchemaAgreement waits until all hosts report the same schema version, it returns a warning when they do not agree
+// within schema_agreement_timeout of the start of the statement, a single deadline covering the wait of the driver after schema changes,
+// a timeout of 0 does not wait
+func (c *Client) awaitSchemaAgreement(ctx context.Context, session schemaAgreementAwaiter, start time.Time) diag.Diagnostics {
+	if c.cluster.MaxWaitSchemaAgreement == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, start.Add(c.cluster.MaxWaitSchemaAgreement))
+	defer cancel()
+
+	err := session.AwaitSchemaAgreement(ctx)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		tflog.Warn(ctx, "Schema agreement not reached", map[string]interface{}{
+			"duration": elapsed.String(),
+			"error":    err.Error(),
+		})
+
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Schema agreement not reached",
+				Detail:   fmt.Sprintf("hosts did not agree on the schema version within %s, the change may not be visible on every host yet: %v", c.cluster.MaxWaitSchemaAgreement, err),
+			},
+		}
+	}
+
+	tflog.Debug(ctx, "Schema agreement reached", map[string]interface{}{
+		"duration": elapsed.String(),
+	})
+
+	return nil
+}
+
+// Close closes the shared session if it was created and the audit log
+func (c *Client) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.session != nil {
+		c.session.Close()
+		c.session = nil
+	}
+
+	if c.audit != nil {
+		c.audit.Close()
+	}
+}
+
+// CloseClients closes the sessions of all clients created by the provider, it is called when the plugin exits
+func CloseClients() {
+	openClientsLock.Lock()
+	defer openClientsLock.Unlock()
+
+	for _, client := range openClients {
+		client.Close()
+	}
+
+	openClients = nil
+}
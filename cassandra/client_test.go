@@ -0,0 +1,121 @@
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestClient_closeWithoutSession(t *testing.T) {
+	client := NewClient(gocql.NewCluster("127.0.0.1"))
+
+	client.Close()
+	CloseClients()
+
+	if len(openClients) != 0 {
+		t.Fatalf("expected no open clients, got %d", len(openClients))
+	}
+}
+
+func TestClient_sessionError(t *testing.T) {
+	cluster := gocql.NewCluster()
+	client := NewClient(cluster)
+	defer CloseClients()
+
+	if _, err := client.Session(context.Background()); err == nil {
+		t.Fatal("expected an error when no hosts are configured")
+	}
+
+	if client.Cluster() != cluster {
+		t.Fatal("expected client to keep the cluster configuration")
+	}
+}
+
+// testSchemaAgreementAwaiter never reaches schema agreement
+type testSchemaAgreementAwaiter struct {
+	deadline time.Time
+}
+
+func (a *testSchemaAgreementAwaiter) AwaitSchemaAgreement(ctx context.Context) error {
+	a.deadline, _ = ctx.Deadline()
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestClient_schemaAgreementWarning(t *testing.T) {
+	cluster := gocql.NewCluster()
+	cluster.MaxWaitSchemaAgreement = time.Second
+	client := NewClient(cluster)
+	defer CloseClients()
+
+	// the driver already waited for most of the timeout while executing the statement
+	start := time.Now().Add(-900 * time.Millisecond)
+	awaiter := &testSchemaAgreementAwaiter{}
+
+	diags := client.awaitSchemaAgreement(context.Background(), awaiter, start)
+
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "Schema agreement not reached" {
+		t.Fatalf("expected a schema agreement warning, got %v", diags)
+	}
+
+	if !awaiter.deadline.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the deadline to be the start of the statement plus the timeout, got %s", awaiter.deadline.Sub(start))
+	}
+}
+
+func TestClient_schemaAgreementDisabled(t *testing.T) {
+	cluster := gocql.NewCluster()
+	cluster.MaxWaitSchemaAgreement = 0
+	client := NewClient(cluster)
+	defer CloseClients()
+
+	awaiter := &testSchemaAgreementAwaiter{}
+
+	if diags := client.awaitSchemaAgreement(context.Background(), awaiter, time.Now()); len(diags) != 0 {
+		t.Fatalf("expected no warning, got %v", diags)
+	}
+
+	if !awaiter.deadline.IsZero() {
+		t.Fatal("expected schema agreement not to be awaited")
+	}
+}
+
+func TestClient_sessionCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// the host accepts connections but never answers the startup
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cluster := gocql.NewCluster("127.0.0.1")
+	cluster.Port = listener.Addr().(*net.TCPAddr).Port
+	cluster.ProtoVersion = 4
+	cluster.ConnectTimeout = time.Minute
+	cluster.Timeout = time.Minute
+	client := NewClient(cluster)
+	defer CloseClients()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Session(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the session creation to stop with the context, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cassandra
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"github.com/pierrec/lz4/v4"
+)
+
+const (
+	compressionNone   = "none"
+	compressionSnappy = "snappy"
+	compressionLZ4    = "lz4"
+)
+
+var (
+	allowedCompressions = []string{compressionNone, compressionSnappy, compressionLZ4}
+)
+
+// lz4Compressor implements gocql.Compressor with the LZ4 framing of the CQL native protocol,
+// a compressed body is the uncompressed length as a 4 byte big endian integer followed by an LZ4 block
+type lz4Compressor struct{}
+
+func (c lz4Compressor) Name() string {
+	return compressionLZ4
+}
+
+func (c lz4Compressor) Encode(data []byte) ([]byte, error) {
+	buffer := make([]byte, 4+lz4.CompressBlockBound(len(data)))
+	binary.BigEndian.PutUint32(buffer, uint32(len(data)))
+
+	n, err := lz4.CompressBlock(data, buffer[4:], nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer[:4+n], nil
+}
+
+func (c lz4Compressor) Decode(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("lz4: compressed body is too short")
+	}
+
+	length := binary.BigEndian.Uint32(data)
+
+	if length == 0 {
+		return nil, nil
+	}
+
+	buffer := make([]byte, length)
+	n, err := lz4.UncompressBlock(data[4:], buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer[:n], nil
+}
+
+// buildCompressor returns the gocql compressor for the compression attribute, nil disables compression
+func buildCompressor(compression string) gocql.Compressor {
+	switch compression {
+	case compressionSnappy:
+		return gocql.SnappyCompressor{}
+	case compressionLZ4:
+		return lz4Compressor{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package cassandra
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressor_roundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("LIST ALL PERMISSIONS OF cassandra_role; "), 100)
+
+	for _, compression := range []string{compressionSnappy, compressionLZ4} {
+		compressor := buildCompressor(compression)
+
+		if compressor.Name() != compression {
+			t.Fatalf("expected %s compressor, got %s", compression, compressor.Name())
+		}
+
+		encoded, err := compressor.Encode(data)
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+
+		if len(encoded) >= len(data) {
+			t.Fatalf("%s: expected compressed data to be smaller", compression)
+		}
+
+		decoded, err := compressor.Decode(encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+
+		if !bytes.Equal(decoded, data) {
+			t.Fatalf("%s: decoded data differs", compression)
+		}
+	}
+
+	if buildCompressor(compressionNone) != nil {
+		t.Fatal("expected no compressor")
+	}
+}
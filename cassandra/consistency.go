@@ -0,0 +1,72 @@
+package cassandra
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// consistencyContextKey holds the consistency overriding the default one for the statements of a resource
+type consistencyContextKey struct{}
+
+// consistencyNames returns the sorted names of allowedConsistencies
+func consistencyNames() []string {
+	names := make([]string, 0, len(allowedConsistencies))
+
+	for name := range allowedConsistencies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// serialConsistencyNames returns the sorted names of allowedSerialConsistencies
+func serialConsistencyNames() []string {
+	names := make([]string, 0, len(allowedSerialConsistencies))
+
+	for name := range allowedSerialConsistencies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// resourceConsistencySchema is the consistency attribute of the resources, it does not change anything in the cluster
+func resourceConsistencySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Consistency level of the statements of this resource, overrides the consistency of the provider",
+		ValidateFunc: validation.StringInSlice(consistencyNames(), false),
+	}
+}
+
+// withConsistency returns a context executing the statements with the consistency of the resource, when it is set
+func withConsistency(ctx context.Context, d *schema.ResourceData) context.Context {
+	if v, ok := d.GetOk("consistency"); ok {
+		return context.WithValue(ctx, consistencyContextKey{}, allowedConsistencies[v.(string)])
+	}
+
+	return ctx
+}
+
+// onlyConsistencyChanged returns whether the update has no statement to run
+func onlyConsistencyChanged(d *schema.ResourceData) bool {
+	return !d.HasChangeExcept("consistency")
+}
+
+// applyConsistency sets the consistency of the context on the query
+func applyConsistency(ctx context.Context, query *gocql.Query) *gocql.Query {
+	if consistency, ok := ctx.Value(consistencyContextKey{}).(gocql.Consistency); ok {
+		return query.Consistency(consistency)
+	}
+
+	return query
+}
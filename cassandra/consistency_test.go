@@ -0,0 +1,87 @@
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_configureInvalidConsistency(t *testing.T) {
+	p := Provider()
+
+	for _, consistency := range []string{"QUORUMM", "quorum", "SERIAL"} {
+		rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"host":        "localhost",
+			"consistency": consistency,
+		})
+
+		if diags := p.Validate(rc); !diags.HasError() {
+			t.Errorf("expected consistency %s to be rejected", consistency)
+		}
+	}
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "localhost",
+		"consistency":        "LOCAL_QUORUM",
+		"serial_consistency": "QUORUM",
+	})
+
+	if diags := p.Validate(rc); !diags.HasError() {
+		t.Error("expected serial_consistency QUORUM to be rejected")
+	}
+}
+
+func TestProvider_configureSerialConsistency(t *testing.T) {
+	defer CloseClients()
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "localhost",
+		"consistency":        "LOCAL_QUORUM",
+		"serial_consistency": "LOCAL_SERIAL",
+	})
+	p := Provider()
+
+	if diags := p.Validate(rc); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	if diags := p.Configure(context.Background(), rc); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	cluster := p.Meta().(*Client).Cluster()
+
+	if cluster.Consistency != gocql.LocalQuorum || cluster.SerialConsistency != gocql.LocalSerial {
+		t.Fatalf("unexpected consistency %s and serial consistency %s", cluster.Consistency, cluster.SerialConsistency)
+	}
+}
+
+func TestConsistency_withConsistency(t *testing.T) {
+	session := &gocql.Session{}
+	d := schema.TestResourceDataRaw(t, resourceCassandraKeyspace().Schema, map[string]interface{}{
+		"name":                 "app",
+		"replication_strategy": "SimpleStrategy",
+		"consistency":          "ALL",
+	})
+
+	query := applyConsistency(withConsistency(context.Background(), d), session.Query(`SELECT * FROM system.local`).Consistency(gocql.Quorum))
+
+	if query.GetConsistency() != gocql.All {
+		t.Fatalf("expected consistency ALL, got %s", query.GetConsistency())
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceCassandraKeyspace().Schema, map[string]interface{}{
+		"name":                 "app",
+		"replication_strategy": "SimpleStrategy",
+	})
+
+	// the consistency of the provider is kept when the resource does not set one
+	query = applyConsistency(withConsistency(context.Background(), d), session.Query(`SELECT * FROM system.local`).Consistency(gocql.Quorum))
+
+	if query.GetConsistency() != gocql.Quorum {
+		t.Fatalf("expected consistency QUORUM, got %s", query.GetConsistency())
+	}
+}
@@ -0,0 +1,62 @@
+package cassandra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// processCredentials is the JSON document printed by the credential process
+type processCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// readPasswordFile returns the content of the password file without the trailing newline
+func readPasswordFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read password file: %w", err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// runCredentialProcess runs the command through the shell and parses the credentials it prints on stdout,
+// the output is never logged nor included in errors as it contains the password
+func runCredentialProcess(ctx context.Context, command string) (*processCredentials, error) {
+	var cmd *exec.Cmd
+
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential process failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	credentials := &processCredentials{}
+
+	if err := json.Unmarshal(stdout.Bytes(), credentials); err != nil {
+		return nil, fmt.Errorf("credential process output is not a JSON object with username and password")
+	}
+
+	if credentials.Password == "" {
+		return nil, fmt.Errorf("credential process output does not contain a password")
+	}
+
+	return credentials, nil
+}
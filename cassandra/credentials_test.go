@@ -0,0 +1,95 @@
+package cassandra
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_configurePasswordFile(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+
+	if err := ioutil.WriteFile(passwordFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":          "asdf",
+		"username":      "cassandra",
+		"password_file": passwordFile,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	authenticator := p.Meta().(*Client).Cluster().Authenticator.(*gocql.PasswordAuthenticator)
+	if authenticator.Username != "cassandra" || authenticator.Password != "from-file" {
+		t.Fatalf("unexpected credentials %s", authenticator.Username)
+	}
+}
+
+func TestProvider_configureCredentialProcess(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "asdf",
+		"credential_process": `echo '{"username": "ci", "password": "from-process"}'`,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	authenticator := p.Meta().(*Client).Cluster().Authenticator.(*gocql.PasswordAuthenticator)
+	if authenticator.Username != "ci" || authenticator.Password != "from-process" {
+		t.Fatalf("unexpected credentials %s", authenticator.Username)
+	}
+}
+
+func TestProvider_configureCredentialProcessFailure(t *testing.T) {
+	for _, command := range []string{
+		`echo "cannot reach vault" >&2; exit 3`,
+		`echo "password=s3cr3t"`,
+	} {
+		rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"host":               "asdf",
+			"credential_process": command,
+		})
+		diags := Provider().Configure(context.Background(), rc)
+		if !diags.HasError() {
+			t.Fatalf("expected %q to fail", command)
+		}
+
+		for _, d := range diags {
+			if strings.Contains(d.Detail, "s3cr3t") {
+				t.Fatalf("diagnostic leaks the credential process output: %s", d.Detail)
+			}
+		}
+	}
+}
+
+func TestProvider_configureConflictingPasswords(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "asdf",
+		"password_file":      "/tmp/password",
+		"credential_process": "echo",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected password_file and credential_process to conflict")
+	}
+}
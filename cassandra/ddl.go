@@ -0,0 +1,65 @@
+package cassandra
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// acquireDDL waits until no other schema change runs and the rate limit allows the next one,
+// the returned function releases the lock
+func (c *Client) acquireDDL(ctx context.Context) (func(), error) {
+	select {
+	case c.ddlLock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	release := func() {
+		c.lastDDL = time.Now()
+		<-c.ddlLock
+	}
+
+	if c.minDDLInterval > 0 && !c.lastDDL.IsZero() {
+		if wait := time.Until(c.lastDDL.Add(c.minDDLInterval)); wait > 0 {
+			tflog.Debug(ctx, "Waiting before the next schema change to honor max_ddl_per_second", map[string]interface{}{
+				"duration": wait.String(),
+			})
+
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				<-c.ddlLock
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// executeSchemaChange runs the schema changing statement while holding the DDL lock, the lock is released
+// once hosts agree on the schema so that the next schema change starts from an agreed schema
+func (c *Client) executeSchemaChange(ctx context.Context, session *gocql.Session, statement string) diag.Diagnostics {
+	release, err := c.acquireDDL(ctx)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	defer release()
+
+	start := time.Now()
+
+	if err := c.query(ctx, session, statement).Exec(); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return c.awaitSchemaAgreement(ctx, session, start)
+}
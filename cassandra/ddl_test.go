@@ -0,0 +1,124 @@
+package cassandra
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDDL_serialized(t *testing.T) {
+	client := NewClient(gocql.NewCluster())
+	defer CloseClients()
+
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		running int
+		maximum int
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			release, err := client.acquireDDL(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			lock.Lock()
+			running++
+			if running > maximum {
+				maximum = running
+			}
+			lock.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+
+			release()
+		}()
+	}
+
+	wg.Wait()
+
+	if maximum != 1 {
+		t.Fatalf("expected schema changes to run one at a time, %d ran concurrently", maximum)
+	}
+}
+
+func TestDDL_rateLimited(t *testing.T) {
+	client := NewClient(gocql.NewCluster())
+	client.minDDLInterval = 50 * time.Millisecond
+	defer CloseClients()
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		release, err := client.acquireDDL(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("expected 3 schema changes to take at least 100ms, took %s", elapsed)
+	}
+}
+
+func TestDDL_cancelledWhileWaiting(t *testing.T) {
+	client := NewClient(gocql.NewCluster())
+	defer CloseClients()
+
+	release, err := client.acquireDDL(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.acquireDDL(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestDDL_configure(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "asdf",
+		"max_ddl_per_second": 4,
+	})
+	p := Provider()
+	if v := p.Validate(rc); v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	if diags := p.Configure(context.Background(), rc); diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	if interval := p.Meta().(*Client).minDDLInterval; interval != 250*time.Millisecond {
+		t.Fatalf("expected 250ms between schema changes, got %s", interval)
+	}
+
+	rc = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "asdf",
+		"max_ddl_per_second": -1,
+	})
+	if v := Provider().Validate(rc); !v.HasError() {
+		t.Fatal("expected negative max_ddl_per_second to fail validation")
+	}
+}
@@ -0,0 +1,77 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// configure configures the provider, Terraform defers the resources when it allows it and the configuration is unknown
+func configure(ctx context.Context, req schema.ConfigureProviderRequest, resp *schema.ConfigureProviderResponse) {
+	resp.Meta, resp.Diagnostics = configureProvider(ctx, req.ResourceData)
+
+	if client, ok := resp.Meta.(*Client); ok && client.deferred() && req.DeferralAllowed {
+		resp.Deferred = &schema.Deferred{Reason: schema.DeferredReasonProviderConfigUnknown}
+	}
+}
+
+// unknownAttributes returns the provider attributes which are unknown during plan,
+// such as the hosts or the password of a cluster created in the same run
+func unknownAttributes(config cty.Value) []string {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() {
+		return nil
+	}
+
+	var unknown []string
+
+	for name, value := range config.AsValueMap() {
+		if !value.IsWhollyKnown() {
+			unknown = append(unknown, name)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// newDeferredClient returns a client which never connects, it is used during plan when the configuration is unknown
+func newDeferredClient(ctx context.Context, d *schema.ResourceData, unknown []string) *Client {
+	tflog.Info(ctx, "Deferring the connection until the provider configuration is known", map[string]interface{}{
+		"unknown_attributes": unknown,
+	})
+
+	client := NewClient(gocql.NewCluster())
+	client.unknownAttributes = unknown
+	client.logContext = ctx
+
+	// an unknown flavor is read as an empty string
+	if flavor := d.Get("flavor").(string); flavor != "" {
+		client.flavor = flavor
+	} else {
+		client.flavor = flavorAuto
+	}
+
+	return client
+}
+
+// deferred returns whether the provider configuration is unknown, the cluster cannot be contacted until it is known
+func (c *Client) deferred() bool {
+	return len(c.unknownAttributes) > 0
+}
+
+// deferredError returns the error reported by operations which need the cluster while the configuration is unknown
+func (c *Client) deferredError() error {
+	return fmt.Errorf("the provider configuration is not known yet (%s), the cluster can only be contacted once it is known", strings.Join(c.unknownAttributes, ", "))
+}
+
+// offline returns whether the cluster must not be contacted, reads keep the state and version checks are skipped
+func (c *Client) offline() bool {
+	return c.renderOnly() || c.deferred()
+}
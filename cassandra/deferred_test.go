@@ -0,0 +1,156 @@
+package cassandra
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDeferred_unknownAttributes(t *testing.T) {
+	config := cty.ObjectVal(map[string]cty.Value{
+		"host":     cty.NullVal(cty.String),
+		"hosts":    cty.ListVal([]cty.Value{cty.StringVal("10.0.0.1"), cty.UnknownVal(cty.String)}),
+		"password": cty.UnknownVal(cty.String),
+		"username": cty.StringVal("cassandra"),
+	})
+
+	if unknown := unknownAttributes(config); !reflect.DeepEqual(unknown, []string{"hosts", "password"}) {
+		t.Fatalf("unexpected unknown attributes %v", unknown)
+	}
+
+	if unknown := unknownAttributes(cty.NullVal(config.Type())); unknown != nil {
+		t.Fatalf("unexpected unknown attributes %v", unknown)
+	}
+}
+
+// testObjectValue returns a value of the schema with the given attributes, the others are null and list blocks are empty
+func testObjectValue(t *testing.T, s *tfprotov5.Schema, attributes map[string]tftypes.Value) tftypes.Value {
+	objectType := s.ValueType().(tftypes.Object)
+	values := map[string]tftypes.Value{}
+
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+
+	for _, block := range s.Block.BlockTypes {
+		if block.Nesting == tfprotov5.SchemaNestedBlockNestingModeList {
+			values[block.TypeName] = tftypes.NewValue(objectType.AttributeTypes[block.TypeName], []tftypes.Value{})
+		}
+	}
+
+	for name, value := range attributes {
+		values[name] = value
+	}
+
+	return tftypes.NewValue(objectType, values)
+}
+
+func TestProvider_configureUnknown(t *testing.T) {
+	defer CloseClients()
+
+	p := Provider()
+	server := schema.NewGRPCProviderServer(p)
+	ctx := context.Background()
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// connecting to the cluster would fail for the preflight and local_dc checks
+	configValue := testObjectValue(t, schemas.Provider, map[string]tftypes.Value{
+		"hosts":     tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, tftypes.UnknownValue),
+		"password":  tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"local_dc":  tftypes.NewValue(tftypes.String, "dc1"),
+		"preflight": tftypes.NewValue(tftypes.Bool, true),
+	})
+
+	config, err := tfprotov5.NewDynamicValue(configValue.Type(), configValue)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{
+		Config:             &config,
+		ClientCapabilities: &tfprotov5.ConfigureProviderClientCapabilities{DeferralAllowed: true},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, diagnostic := range resp.Diagnostics {
+		t.Fatalf("unexpected diagnostic %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	client := p.Meta().(*Client)
+
+	if !reflect.DeepEqual(client.unknownAttributes, []string{"hosts", "password"}) {
+		t.Fatalf("unexpected unknown attributes %v", client.unknownAttributes)
+	}
+
+	if _, err := client.Session(context.Background()); err == nil || !strings.Contains(err.Error(), "not known yet (hosts, password)") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := client.requireFeature(ctx, featureTransientReplication); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	stateValue := testObjectValue(t, schemas.ResourceSchemas["cassandra_role"], map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, "app"),
+		"name": tftypes.NewValue(tftypes.String, "app"),
+	})
+
+	state, err := tfprotov5.NewDynamicValue(stateValue.Type(), stateValue)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the resources are deferred to the next run, once the configuration is known
+	read, err := server.ReadResource(ctx, &tfprotov5.ReadResourceRequest{
+		TypeName:           "cassandra_role",
+		CurrentState:       &state,
+		ClientCapabilities: &tfprotov5.ReadResourceClientCapabilities{DeferralAllowed: true},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.Deferred == nil || read.Deferred.Reason != tfprotov5.DeferredReasonProviderConfigUnknown {
+		t.Fatalf("expected the read to be deferred, got %+v", read.Deferred)
+	}
+}
+
+func TestDeferred_roleRead(t *testing.T) {
+	defer CloseClients()
+
+	client := NewClient(gocql.NewCluster())
+	client.unknownAttributes = []string{"hosts"}
+
+	d := schema.TestResourceDataRaw(t, resourceCassandraRole().Schema, map[string]interface{}{
+		"name":     "app",
+		"password": "secret-password",
+	})
+	d.SetId("app")
+
+	// nothing is read, the state is kept until the configuration is known
+	if diags := resourceRoleRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	if d.Id() != "app" {
+		t.Fatalf("expected the role to be kept, got id %q", d.Id())
+	}
+}
@@ -0,0 +1,169 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	flavorAuto      = "auto"
+	flavorCassandra = "cassandra"
+	flavorScylla    = "scylla"
+
+	flavorAWSKeyspaces = "aws_keyspaces"
+)
+
+var (
+	allowedFlavors = []string{flavorAuto, flavorCassandra, flavorScylla, flavorAWSKeyspaces}
+
+	// detectableFlavors are the flavors auto detection can tell apart, others must be set explicitly
+	detectableFlavors = []string{flavorCassandra, flavorScylla}
+
+	// flavors holds the system tables and quirks of each database
+	flavors = map[string]*flavor{
+		flavorCassandra: {
+			name:        flavorCassandra,
+			rolesTables: []string{"system_auth.roles"},
+		},
+		flavorScylla: {
+			name: flavorScylla,
+			// auth tables moved from system_auth to system in recent versions
+			rolesTables:          []string{"system.roles", "system_auth.roles"},
+			keyspacesTable:       "system_schema.keyspaces",
+			unsupportedResources: []string{resourceMbean, resourceMbeans, resourceAllMbeans},
+		},
+		flavorAWSKeyspaces: {
+			name: flavorAWSKeyspaces,
+			// roles and permissions are managed with IAM
+			keyspacesTable:        "system_schema_mcs.keyspaces",
+			replicationStrategies: []string{"SingleRegionStrategy"},
+			unsupportedTypes:      []string{"cassandra_role", "cassandra_grant"},
+		},
+	}
+)
+
+// flavor describes how to query a database compatible with the CQL protocol
+type flavor struct {
+	name string
+	// rolesTables are tried in order, the first existing one is used
+	rolesTables []string
+	// keyspacesTable is read instead of the driver keyspace metadata when set
+	keyspacesTable       string
+	unsupportedResources []string
+	// replicationStrategies restricts the keyspace replication strategies when set
+	replicationStrategies []string
+	unsupportedTypes      []string
+}
+
+// supportsResource returns an error when the grant resource type is not supported
+func (f *flavor) supportsResource(resourceType string) error {
+	for _, unsupported := range f.unsupportedResources {
+		if unsupported == resourceType {
+			return fmt.Errorf("resource type %s is not supported by %s", resourceType, f.name)
+		}
+	}
+
+	return nil
+}
+
+// supportsType returns an error when the terraform resource type is not supported
+func (f *flavor) supportsType(typeName string) error {
+	for _, unsupported := range f.unsupportedTypes {
+		if unsupported == typeName {
+			return fmt.Errorf("%s is not supported by %s", typeName, f.name)
+		}
+	}
+
+	return nil
+}
+
+// supportsReplicationStrategy returns an error when the keyspace replication strategy is not supported
+func (f *flavor) supportsReplicationStrategy(strategy string) error {
+	if len(f.replicationStrategies) == 0 {
+		return nil
+	}
+
+	for _, supported := range f.replicationStrategies {
+		if supported == strategy {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("replication strategy %s is not supported by %s - must be one of %s", strategy, f.name, strings.Join(f.replicationStrategies, ", "))
+}
+
+// flavorsLackResource returns whether any detectable flavor does not support the grant resource type,
+// the flavor only needs to be detected for those resource types
+func flavorsLackResource(resourceType string) bool {
+	for _, name := range detectableFlavors {
+		if flavors[name].supportsResource(resourceType) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectFlavor inspects the columns of system.local, scylla adds its own columns to the table
+func detectFlavor(ctx context.Context, session *gocql.Session) (string, error) {
+	row := make(map[string]interface{})
+
+	if err := session.Query(`SELECT * FROM system.local`).WithContext(ctx).MapScan(row); err != nil {
+		return "", fmt.Errorf("cannot detect flavor from system.local: %w", err)
+	}
+
+	for column := range row {
+		if strings.HasPrefix(column, "scylla_") || column == "supported_features" {
+			return flavorScylla, nil
+		}
+	}
+
+	return flavorCassandra, nil
+}
+
+// isUnconfiguredTable returns whether the error is caused by querying a table which does not exist
+func isUnconfiguredTable(err error) bool {
+	requestError, ok := err.(gocql.RequestError)
+
+	return ok && requestError.Code() == gocql.ErrCodeInvalid && strings.Contains(strings.ToLower(requestError.Message()), "unconfigured table")
+}
+
+// knownFlavor returns the flavor without connecting to the cluster, or nil when it has not been detected yet
+func (c *Client) knownFlavor() *flavor {
+	c.flavorLock.Lock()
+	defer c.flavorLock.Unlock()
+
+	return flavors[c.flavor]
+}
+
+// Flavor returns the flavor of the database, detecting it on first use when it is set to auto
+func (c *Client) Flavor(ctx context.Context) (*flavor, error) {
+	c.flavorLock.Lock()
+	defer c.flavorLock.Unlock()
+
+	if c.flavor == flavorAuto {
+		session, err := c.Session(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		detected, err := detectFlavor(ctx, session)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tflog.Debug(ctx, "Detected flavor", map[string]interface{}{
+			"flavor": detected,
+		})
+
+		c.flavor = detected
+	}
+
+	return flavors[c.flavor], nil
+}
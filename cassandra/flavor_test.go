@@ -0,0 +1,91 @@
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testRequestError is a server error as returned by gocql
+type testRequestError struct {
+	code    int
+	message string
+}
+
+func (e testRequestError) Code() int       { return e.code }
+func (e testRequestError) Message() string { return e.message }
+func (e testRequestError) Error() string   { return e.message }
+
+func TestFlavor_supportsResource(t *testing.T) {
+	if err := flavors[flavorCassandra].supportsResource(resourceMbean); err != nil {
+		t.Fatalf("expected cassandra to support mbeans, got %v", err)
+	}
+
+	for _, resourceType := range []string{resourceMbean, resourceMbeans, resourceAllMbeans} {
+		if err := flavors[flavorScylla].supportsResource(resourceType); err == nil {
+			t.Fatalf("expected scylla to reject %s", resourceType)
+		}
+	}
+
+	if err := flavors[flavorScylla].supportsResource(resourceKeyspace); err != nil {
+		t.Fatalf("expected scylla to support keyspaces, got %v", err)
+	}
+
+	if flavorsLackResource(resourceTable) || !flavorsLackResource(resourceAllMbeans) {
+		t.Fatal("unexpected resource support across flavors")
+	}
+}
+
+func TestFlavor_isUnconfiguredTable(t *testing.T) {
+	if !isUnconfiguredTable(testRequestError{gocql.ErrCodeInvalid, "unconfigured table roles"}) {
+		t.Fatal("expected unconfigured table error to be detected")
+	}
+
+	if isUnconfiguredTable(testRequestError{gocql.ErrCodeUnauthorized, "unconfigured table roles"}) {
+		t.Fatal("expected unauthorized error not to be an unconfigured table")
+	}
+
+	if isUnconfiguredTable(gocql.ErrNotFound) {
+		t.Fatal("expected not found error not to be an unconfigured table")
+	}
+}
+
+func TestFlavor_configure(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":   "asdf",
+		"flavor": "scylla",
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	// an explicit flavor is used without connecting to the cluster
+	flavor, err := p.Meta().(*Client).Flavor(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flavor.name != flavorScylla {
+		t.Fatalf("expected flavor %s, got %s", flavorScylla, flavor.name)
+	}
+}
+
+func TestFlavor_invalid(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":   "asdf",
+		"flavor": "dse",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected dse flavor to fail validation")
+	}
+}
@@ -0,0 +1,220 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// frameworkProvider is the terraform-plugin-framework provider served next to the SDKv2 provider,
+// resources are ported to it one at a time
+type frameworkProvider struct {
+	sdkProvider *schema.Provider
+}
+
+// NewFrameworkProvider returns the framework provider sharing the schema and the client of the SDKv2 provider
+func NewFrameworkProvider(sdkProvider *schema.Provider) provider.Provider {
+	return &frameworkProvider{sdkProvider: sdkProvider}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "cassandra"
+}
+
+// Schema returns the schema of the SDKv2 provider, the mux server requires both providers to declare the same schema
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	sdkSchema, err := schema.NewGRPCProviderServer(p.sdkProvider).GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read the SDKv2 provider schema", err.Error())
+		return
+	}
+
+	block, err := frameworkProviderBlock(sdkSchema.Provider.Block)
+
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to convert the SDKv2 provider schema", err.Error())
+		return
+	}
+
+	resp.Schema = providerschema.Schema{
+		Description: sdkSchema.Provider.Block.Description,
+		Attributes:  block.Attributes,
+		Blocks:      block.Blocks,
+	}
+}
+
+// Configure reuses the client of the SDKv2 provider, which the mux server configures first
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	client, ok := p.sdkProvider.Meta().(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError("Unable to configure the framework provider", "the SDKv2 provider is not configured")
+		return
+	}
+
+	if client.deferred() && req.ClientCapabilities.DeferralAllowed {
+		resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+	}
+
+	resp.DataSourceData = client
+	resp.ResourceData = client
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+// NewProviderServer returns the protocol 5 server muxing the SDKv2 provider and the framework provider
+func NewProviderServer(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	sdkProvider := Provider()
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, sdkProvider.GRPCProvider, providerserver.NewProtocol5(NewFrameworkProvider(sdkProvider)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+// frameworkProviderBlock converts a protocol block of the SDKv2 provider schema to framework attributes and blocks
+func frameworkProviderBlock(block *tfprotov5.SchemaBlock) (providerschema.NestedBlockObject, error) {
+	object := providerschema.NestedBlockObject{
+		Attributes: map[string]providerschema.Attribute{},
+		Blocks:     map[string]providerschema.Block{},
+	}
+
+	for _, attribute := range block.Attributes {
+		converted, err := frameworkProviderAttribute(attribute)
+
+		if err != nil {
+			return object, err
+		}
+
+		object.Attributes[attribute.Name] = converted
+	}
+
+	for _, nested := range block.BlockTypes {
+		if nested.Nesting != tfprotov5.SchemaNestedBlockNestingModeList {
+			return object, fmt.Errorf("block %s: unsupported nesting mode %s", nested.TypeName, nested.Nesting)
+		}
+
+		nestedObject, err := frameworkProviderBlock(nested.Block)
+
+		if err != nil {
+			return object, fmt.Errorf("block %s: %w", nested.TypeName, err)
+		}
+
+		object.Blocks[nested.TypeName] = providerschema.ListNestedBlock{
+			Description:        nested.Block.Description,
+			DeprecationMessage: deprecationMessage(nested.Block.Deprecated),
+			NestedObject: providerschema.NestedBlockObject{
+				Attributes: nestedObject.Attributes,
+				Blocks:     nestedObject.Blocks,
+			},
+		}
+	}
+
+	return object, nil
+}
+
+// frameworkProviderAttribute converts a protocol attribute of the SDKv2 provider schema to a framework attribute
+func frameworkProviderAttribute(attribute *tfprotov5.SchemaAttribute) (providerschema.Attribute, error) {
+	deprecation := deprecationMessage(attribute.Deprecated)
+
+	switch {
+	case attribute.Type.Is(tftypes.String):
+		return providerschema.StringAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecation,
+		}, nil
+	case attribute.Type.Is(tftypes.Number):
+		return providerschema.NumberAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecation,
+		}, nil
+	case attribute.Type.Is(tftypes.Bool):
+		return providerschema.BoolAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecation,
+		}, nil
+	case attribute.Type.Is(tftypes.List{}):
+		elementType, err := frameworkElementType(attribute.Type.(tftypes.List).ElementType)
+
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", attribute.Name, err)
+		}
+
+		return providerschema.ListAttribute{
+			ElementType:        elementType,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecation,
+		}, nil
+	case attribute.Type.Is(tftypes.Map{}):
+		elementType, err := frameworkElementType(attribute.Type.(tftypes.Map).ElementType)
+
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", attribute.Name, err)
+		}
+
+		return providerschema.MapAttribute{
+			ElementType:        elementType,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecation,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("attribute %s: unsupported type %s", attribute.Name, attribute.Type)
+}
+
+func frameworkElementType(elementType tftypes.Type) (attr.Type, error) {
+	switch {
+	case elementType.Is(tftypes.String):
+		return types.StringType, nil
+	case elementType.Is(tftypes.Number):
+		return types.NumberType, nil
+	case elementType.Is(tftypes.Bool):
+		return types.BoolType, nil
+	}
+
+	return nil, fmt.Errorf("unsupported element type %s", elementType)
+}
+
+func deprecationMessage(deprecated bool) string {
+	if deprecated {
+		return "Deprecated"
+	}
+
+	return ""
+}
@@ -0,0 +1,33 @@
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+)
+
+func TestProvider_serverSchema(t *testing.T) {
+	providerServer, err := NewProviderServer(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := providerServer().GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the mux server reports an error when the schemas of the SDKv2 and framework providers differ
+	for _, diagnostic := range resp.Diagnostics {
+		t.Errorf("unexpected diagnostic %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	for _, name := range []string{"cassandra_keyspace", "cassandra_role", "cassandra_grant"} {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("expected resource %s to be served", name)
+		}
+	}
+}
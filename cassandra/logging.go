@@ -0,0 +1,93 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	logFieldResource      = "resource"
+	logFieldOperation     = "operation"
+	logFieldKeyspace      = "keyspace"
+	logFieldRole          = "role"
+	logFieldStatementKind = "statement_kind"
+)
+
+var (
+	// resourceLogFields is the log field holding the id of each resource type
+	resourceLogFields = map[string]string{
+		"cassandra_keyspace": logFieldKeyspace,
+		"cassandra_role":     logFieldRole,
+	}
+)
+
+// withResource returns a context attributing the statements executed with it to the resource and operation,
+// in the logs and in the audit log
+func withResource(ctx context.Context, typeName string, id string, operation string) context.Context {
+	address := fmt.Sprintf("%s.%s", typeName, id)
+
+	ctx = tflog.SetField(ctx, logFieldResource, address)
+	ctx = tflog.SetField(ctx, logFieldOperation, operation)
+
+	if field, ok := resourceLogFields[typeName]; ok {
+		ctx = tflog.SetField(ctx, field, id)
+	}
+
+	return context.WithValue(ctx, auditContextKey{}, auditResource{Address: address, Operation: operation})
+}
+
+// statementKind returns the kind of a statement such as CREATE KEYSPACE, GRANT or SELECT
+func statementKind(statement string) string {
+	words := strings.Fields(strings.ToUpper(statement))
+
+	if len(words) == 0 {
+		return ""
+	}
+
+	switch words[0] {
+	case "CREATE", "ALTER", "DROP":
+		if len(words) > 1 {
+			return words[0] + " " + words[1]
+		}
+	}
+
+	return words[0]
+}
+
+// hostAddress returns the address and port used to connect to the host
+func hostAddress(host *gocql.HostInfo) string {
+	if host == nil {
+		return ""
+	}
+
+	return net.JoinHostPort(host.ConnectAddress().String(), strconv.Itoa(host.Port()))
+}
+
+// ObserveQuery traces every statement with its latency, coordinator and attempt, statements of resources are also audited
+func (c *Client) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	fields := map[string]interface{}{
+		"statement":   redactStatement(q.Statement),
+		"coordinator": hostAddress(q.Host),
+		"latency_ms":  float64(q.End.Sub(q.Start).Microseconds()) / 1000,
+		"attempt":     q.Attempt,
+		"rows":        q.Rows,
+	}
+
+	if q.Err != nil {
+		fields["error"] = q.Err.Error()
+
+		tflog.Trace(ctx, "Statement failed", fields)
+	} else {
+		tflog.Trace(ctx, "Statement executed", fields)
+	}
+
+	if _, ok := ctx.Value(auditContextKey{}).(auditResource); ok && c.audit != nil {
+		c.audit.ObserveQuery(ctx, q)
+	}
+}
@@ -0,0 +1,60 @@
+package cassandra
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func TestLogging_statementKind(t *testing.T) {
+	for statement, expected := range map[string]string{
+		`CREATE KEYSPACE app WITH REPLICATION = {}`: "CREATE KEYSPACE",
+		`alter role 'app' with login = true`:        "ALTER ROLE",
+		`GRANT SELECT ON ALL KEYSPACES TO "app"`:    "GRANT",
+		`select role from system_auth.roles`:        "SELECT",
+		`DROP`:                                      "DROP",
+		``:                                          "",
+	} {
+		if kind := statementKind(statement); kind != expected {
+			t.Fatalf("expected %q for %q, got %q", expected, statement, kind)
+		}
+	}
+}
+
+func TestLogging_observeQueryAuditsResourceStatements(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	audit, err := openAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(gocql.NewCluster())
+	client.audit = audit
+	defer CloseClients()
+
+	if client.Cluster().QueryObserver != client || client.Cluster().ConnectObserver != client {
+		t.Fatal("expected client to observe queries and connections")
+	}
+
+	now := time.Now()
+
+	// statements of the driver or of plan time checks are traced only
+	client.ObserveQuery(context.Background(), gocql.ObservedQuery{Statement: `SELECT release_version FROM system.local`, Start: now, End: now})
+	client.ObserveQuery(withResource(context.Background(), "cassandra_keyspace", "app", operationDelete), gocql.ObservedQuery{Statement: `DROP KEYSPACE app`, Start: now, End: now})
+	audit.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"resource":"cassandra_keyspace.app"`) {
+		t.Fatalf("expected only the resource statement to be audited, got %s", content)
+	}
+}
@@ -0,0 +1,152 @@
+package cassandra
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// preflightReport is what the preflight check learned about the cluster and the login role
+type preflightReport struct {
+	ReleaseVersion  string
+	ClusterName     string
+	ProtocolVersion int
+	Role            string
+	SuperUser       bool
+	Permissions     []string
+}
+
+// preflight connects to the cluster, authenticates and reads the cluster version, name and the permissions of the login role
+func (c *Client) preflight(ctx context.Context, username string) (*preflightReport, error) {
+	session, err := c.Session(ctx)
+
+	if err != nil {
+		return nil, c.connectionError(err)
+	}
+
+	report := &preflightReport{Role: username, ProtocolVersion: c.cluster.ProtoVersion}
+
+	err = session.Query(`SELECT release_version, cluster_name FROM system.local`).WithContext(ctx).Scan(&report.ReleaseVersion, &report.ClusterName)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read release_version and cluster_name from system.local: %w", err)
+	}
+
+	if capabilities, err := newCapabilities(report.ReleaseVersion); err == nil {
+		c.capabilitiesLock.Lock()
+		c.capabilities = capabilities
+		c.capabilitiesLock.Unlock()
+	}
+
+	if username == "" {
+		return report, nil
+	}
+
+	flavor, err := c.Flavor(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(flavor.rolesTables) == 0 {
+		// roles are not managed with CQL, service specific credentials have no role to read
+		return report, nil
+	}
+
+	_, _, superUser, _, err := readRole(ctx, c, username)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read login role %s: %w", username, err)
+	}
+
+	report.SuperUser = superUser
+
+	if superUser {
+		return report, nil
+	}
+
+	iter := session.Query(fmt.Sprintf(`LIST ALL PERMISSIONS OF "%s"`, username)).WithContext(ctx).Iter()
+	row := make(map[string]interface{})
+
+	for iter.MapScan(row) {
+		report.Permissions = append(report.Permissions, fmt.Sprintf("%v ON %v", row["permission"], row["resource"]))
+		row = make(map[string]interface{})
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("cannot list permissions of login role %s: %w", username, err)
+	}
+
+	return report, nil
+}
+
+func (r *preflightReport) String() string {
+	role := r.Role
+
+	if role == "" {
+		role = "anonymous"
+	} else if r.SuperUser {
+		role += " (superuser)"
+	} else {
+		role += fmt.Sprintf(" (permissions: %s)", strings.Join(r.Permissions, ", "))
+	}
+
+	return fmt.Sprintf("cluster %s, release_version %s, protocol version %d, login role %s", r.ClusterName, r.ReleaseVersion, r.ProtocolVersion, role)
+}
+
+// connectionError adds the last error observed while connecting to a host and the likely cause to a session error
+func (c *Client) connectionError(err error) error {
+	if connectError := c.lastConnectError(); connectError != nil {
+		err = fmt.Errorf("%v: %w", err, connectError)
+	}
+
+	return fmt.Errorf("%s: %w", explainConnectionError(err), err)
+}
+
+// explainConnectionError turns errors of session creation into a short explanation of the likely cause
+func explainConnectionError(err error) string {
+	var (
+		recordHeaderError tls.RecordHeaderError
+		unknownAuthority  x509.UnknownAuthorityError
+		hostnameError     x509.HostnameError
+		certificateError  x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.As(err, &recordHeaderError):
+		return "TLS handshake failed: server does not speak TLS on this port, disable use_ssl or check the port"
+	case errors.As(err, &unknownAuthority):
+		return "TLS handshake failed: server certificate is signed by an unknown authority, check root_ca"
+	case errors.As(err, &hostnameError):
+		return "TLS handshake failed: server certificate does not match the host, check tls_server_name"
+	case errors.As(err, &certificateError):
+		return "TLS handshake failed: server certificate is invalid"
+	}
+
+	message := strings.ToLower(err.Error())
+
+	for _, explanation := range []struct {
+		fragments []string
+		reason    string
+	}{
+		{[]string{"certificate required", "bad certificate"}, "TLS handshake failed: server requires client cert, set client_cert and client_key"},
+		{[]string{"unknown certificate authority"}, "TLS handshake failed: server does not trust the client certificate"},
+		{[]string{"and/or password are incorrect", "bad credentials", "authentication failed"}, "authentication failed: check username and password"},
+		{[]string{"connection refused"}, "connection refused: check host and port"},
+		{[]string{"no such host"}, "host not found: check host"},
+		{[]string{"i/o timeout", "deadline exceeded"}, "connection timed out: check host, port and firewall rules"},
+		{[]string{"eof", "connection reset"}, "connection closed by the server: the cluster may require TLS, check use_ssl"},
+		{[]string{"no hosts available"}, "no host could be reached: check host, port and local_dc"},
+	} {
+		for _, fragment := range explanation.fragments {
+			if strings.Contains(message, fragment) {
+				return explanation.reason
+			}
+		}
+	}
+
+	return "cannot connect to the cluster"
+}
@@ -0,0 +1,111 @@
+package cassandra
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestPreflight_connectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":      "127.0.0.1",
+		"port":      port,
+		"preflight": true,
+	})
+	diags := Provider().Configure(context.Background(), rc)
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail, "connection refused: check host and port") {
+		t.Fatalf("unexpected diagnostics %+v", diags)
+	}
+}
+
+func TestPreflight_localDatacenter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	for preflight, summary := range map[bool]string{true: "Preflight check failed", false: "Invalid local datacenter"} {
+		rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"host":      "127.0.0.1",
+			"port":      port,
+			"local_dc":  "dc1",
+			"preflight": preflight,
+		})
+		diags := Provider().Configure(context.Background(), rc)
+		if len(diags) != 1 || diags[0].Summary != summary || !strings.Contains(diags[0].Detail, "connection refused: check host and port") {
+			t.Fatalf("preflight %t: unexpected diagnostics %+v", preflight, diags)
+		}
+	}
+}
+
+func TestPreflight_clientCertificateRequired(t *testing.T) {
+	serverCert, serverKey := testGenerateCertificate(t)
+
+	certificate, err := tls.X509KeyPair([]byte(serverCert), []byte(serverKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "127.0.0.1",
+		"port":               listener.Addr().(*net.TCPAddr).Port,
+		"use_ssl":            true,
+		"root_ca":            serverCert,
+		"tls_server_name":    "localhost",
+		"connection_timeout": 2000,
+		"preflight":          true,
+	})
+	diags := Provider().Configure(context.Background(), rc)
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail, "server requires client cert") {
+		t.Fatalf("unexpected diagnostics %+v", diags)
+	}
+}
+
+func TestPreflight_explainConnectionError(t *testing.T) {
+	for message, expected := range map[string]string{
+		"dial tcp 10.0.0.1:9042: i/o timeout":                                   "connection timed out",
+		"Provided username cassandra and/or password are incorrect":             "authentication failed",
+		"dial tcp: lookup cassandra.invalid: no such host":                      "host not found",
+		"gocql: no response received from cassandra within timeout period: EOF": "the cluster may require TLS",
+		"something unexpected":                                                  "cannot connect to the cluster",
+	} {
+		if explanation := explainConnectionError(errors.New(message)); !strings.Contains(explanation, expected) {
+			t.Errorf("%s: expected %q, got %q", message, expected, explanation)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	protocolVersionAuto = "auto"
+
+	responseVersionFlag = 0x80
+)
+
+func validateProtocolVersion(i interface{}, key string) ([]string, []error) {
+	value := i.(string)
+
+	if value == protocolVersionAuto {
+		return nil, nil
+	}
+
+	version, err := strconv.Atoi(value)
+
+	if err != nil || version < 1 || version > 5 {
+		return nil, []error{fmt.Errorf("%s: must be %s or a version between 1 and 5, got %s", key, protocolVersionAuto, value)}
+	}
+
+	return nil, nil
+}
+
+// protocolVersionName returns the protocol version for messages, auto when gocql negotiates it
+func protocolVersionName(version int) string {
+	if version == 0 {
+		return protocolVersionAuto
+	}
+
+	return strconv.Itoa(version)
+}
+
+// ObserveFrameHeader records the protocol version of the frames received, which is the version gocql negotiated
+// when protocol_version is auto
+func (c *Client) ObserveFrameHeader(ctx context.Context, header gocql.ObservedFrameHeader) {
+	c.protocolVersionLock.Lock()
+	c.protocolVersion = int(header.Version) &^ responseVersionFlag
+	c.protocolVersionLock.Unlock()
+}
+
+// observedProtocolVersion returns the protocol version of the last frame received
+func (c *Client) observedProtocolVersion() int {
+	c.protocolVersionLock.Lock()
+	defer c.protocolVersionLock.Unlock()
+
+	return c.protocolVersion
+}
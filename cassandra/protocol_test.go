@@ -0,0 +1,52 @@
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProtocol_observeFrameHeader(t *testing.T) {
+	client := NewClient(gocql.NewCluster())
+	defer CloseClients()
+
+	if client.Cluster().FrameHeaderObserver != client {
+		t.Fatal("expected the client to observe frame headers")
+	}
+
+	client.ObserveFrameHeader(context.Background(), gocql.ObservedFrameHeader{Version: 3 | responseVersionFlag})
+
+	if version := client.observedProtocolVersion(); version != 3 {
+		t.Fatalf("expected protocol version 3, got %d", version)
+	}
+}
+
+func TestProtocol_configureAuto(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":             "asdf",
+		"protocol_version": "auto",
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if version := p.Meta().(*Client).Cluster().ProtoVersion; version != 0 {
+		t.Fatalf("expected protocol version to be negotiated later, got %d", version)
+	}
+
+	rc = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":             "asdf",
+		"protocol_version": "latest",
+	})
+	if v := Provider().Validate(rc); !v.HasError() {
+		t.Fatal("expected invalid protocol version to fail validation")
+	}
+}
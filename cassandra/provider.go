@@ -4,12 +4,17 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
-	"log"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -35,6 +40,16 @@ var (
 		"EACH_QUORUM":  gocql.EachQuorum,
 		"LOCAL_ONE":    gocql.LocalOne,
 	}
+
+	allowedSerialConsistencies = map[string]gocql.SerialConsistency{
+		"SERIAL":       gocql.Serial,
+		"LOCAL_SERIAL": gocql.LocalSerial,
+	}
+)
+
+const (
+	retryPolicySimple      = "simple"
+	retryPolicyExponential = "exponential"
 )
 
 // Provider returns a terraform.ResourceProvider
@@ -45,7 +60,7 @@ func Provider() *schema.Provider {
 			"cassandra_role":     resourceCassandraRole(),
 			"cassandra_grant":    resourceCassandraGrant(),
 		},
-		ConfigureContextFunc: configureProvider,
+		ConfigureProvider: configure,
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
 				Type:        schema.TypeString,
@@ -61,6 +76,18 @@ func Provider() *schema.Provider {
 				Description: "Cassandra password",
 				Sensitive:   true,
 			},
+			"password_file": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path to a file containing the Cassandra password",
+				ConflictsWith: []string{"password", "credential_process"},
+			},
+			"credential_process": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Command printing a JSON object with username and password, run when the provider is configured",
+				ConflictsWith: []string{"password", "password_file"},
+			},
 			"port": &schema.Schema{
 				Type:         schema.TypeInt,
 				Optional:     true,
@@ -73,7 +100,7 @@ func Provider() *schema.Provider {
 				DefaultFunc:  schema.EnvDefaultFunc("CASSANDRA_HOST", nil),
 				Description:  "Cassandra host",
 				Optional:     true,
-				ExactlyOneOf: []string{"host", "hosts"},
+				ExactlyOneOf: []string{"host", "hosts", "secure_connect_bundle"},
 			},
 			"hosts": &schema.Schema{
 				Type: schema.TypeList,
@@ -84,6 +111,12 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				Description: "Cassandra hosts",
 			},
+			"secure_connect_bundle": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Path to a secure connect bundle zip file, or its base64 encoded content. Sets hosts, port and TLS configuration",
+			},
 			"host_filter": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -96,10 +129,52 @@ func Provider() *schema.Provider {
 				Default:     1000,
 				Description: "Connection timeout in milliseconds",
 			},
+			"request_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60000,
+				Description:  "Timeout of a single request to the cluster in milliseconds",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"schema_agreement_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60000,
+				Description:  "Maximum time in milliseconds to wait for all hosts to agree on the schema version after a change, 0 does not wait",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"compression": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      compressionNone,
+				Description:  fmt.Sprintf("Compression of the CQL protocol frames - must be one of %s", strings.Join(allowedCompressions, ", ")),
+				ValidateFunc: validation.StringInSlice(allowedCompressions, false),
+			},
+			"num_conns": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2,
+				Description:  "Number of connections opened to each host",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"keepalive": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "TCP keepalive period of the connections in milliseconds, 0 disables keepalive, the Go default of 15s is used when unset",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"reconnect_interval": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60000,
+				Description:  "Interval in milliseconds between attempts to reconnect to hosts which are down, 0 disables reconnection",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 			"root_ca": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Use root CA to connect to Cluster. Applies only when useSSL is enabled",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Use root CA to connect to Cluster. Applies only when useSSL is enabled",
+				ConflictsWith: []string{"root_ca_file"},
 				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
 					rootCA := i.(string)
 
@@ -124,6 +199,98 @@ func Provider() *schema.Provider {
 					return nil
 				},
 			},
+			"client_cert": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Client certificate in PEM format used for mutual TLS authentication. Applies only when useSSL is enabled",
+				RequiredWith:  []string{"client_key"},
+				ConflictsWith: []string{"client_cert_file"},
+				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
+					clientCert := i.(string)
+
+					if clientCert == "" {
+						return nil
+					}
+
+					certPool := x509.NewCertPool()
+					ok := certPool.AppendCertsFromPEM([]byte(clientCert))
+
+					if !ok {
+						return diag.Diagnostics{
+							{
+								Severity:      diag.Error,
+								Summary:       "Invalid PEM",
+								Detail:        "client_cert: invalid PEM",
+								AttributePath: path,
+							},
+						}
+					}
+
+					return nil
+				},
+			},
+			"client_key": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Client private key in PEM format used for mutual TLS authentication. Applies only when useSSL is enabled",
+				RequiredWith:  []string{"client_cert"},
+				ConflictsWith: []string{"client_key_file"},
+				ValidateDiagFunc: func(i interface{}, path cty.Path) diag.Diagnostics {
+					clientKey := i.(string)
+
+					if clientKey == "" {
+						return nil
+					}
+
+					block, _ := pem.Decode([]byte(clientKey))
+
+					if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+						return diag.Diagnostics{
+							{
+								Severity:      diag.Error,
+								Summary:       "Invalid PEM",
+								Detail:        "client_key: invalid PEM private key",
+								AttributePath: path,
+							},
+						}
+					}
+
+					return nil
+				},
+			},
+			"root_ca_file": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path to a file containing the root CA in PEM format. Applies only when useSSL is enabled",
+				ConflictsWith: []string{"root_ca"},
+			},
+			"client_cert_file": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path to a file containing the client certificate in PEM format. Applies only when useSSL is enabled",
+				RequiredWith:  []string{"client_key_file"},
+				ConflictsWith: []string{"client_cert"},
+			},
+			"client_key_file": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path to a file containing the client private key in PEM format. Applies only when useSSL is enabled",
+				RequiredWith:  []string{"client_cert_file"},
+				ConflictsWith: []string{"client_key"},
+			},
+			"tls_server_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Server name used to verify the hostname on the certificates returned by the cluster. Applies only when useSSL is enabled",
+			},
+			"host_verification": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Verify the hostname of the certificates returned by the cluster, the certificate chain is always verified. Applies only when useSSL is enabled",
+			},
 			"use_ssl": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -138,16 +305,24 @@ func Provider() *schema.Provider {
 				ValidateFunc: validation.StringInSlice([]string{"TLS1.0", "TLS1.1", "TLS1.2", "TLS1.3"}, false),
 			},
 			"protocol_version": &schema.Schema{
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     4,
-				Description: "CQL Binary Protocol Version",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "4",
+				Description:  fmt.Sprintf("CQL Binary Protocol Version, %s negotiates the highest version supported by the cluster", protocolVersionAuto),
+				ValidateFunc: validateProtocolVersion,
 			},
 			"consistency": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     gocql.Quorum.String(),
-				Description: "Default consistency level",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      gocql.Quorum.String(),
+				Description:  fmt.Sprintf("Default consistency level - must be one of %s", strings.Join(consistencyNames(), ", ")),
+				ValidateFunc: validation.StringInSlice(consistencyNames(), false),
+			},
+			"serial_consistency": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  fmt.Sprintf("Serial consistency level of conditional statements - must be one of %s", strings.Join(serialConsistencyNames(), ", ")),
+				ValidateFunc: validation.StringInSlice(serialConsistencyNames(), false),
 			},
 			"cql_version": &schema.Schema{
 				Type:        schema.TypeString,
@@ -161,6 +336,165 @@ func Provider() *schema.Provider {
 				// Default:     "system",
 				Description: "Initial Keyspace",
 			},
+			"retry": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Retry policy applied to statements failing with a transient error",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"policy": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      retryPolicyExponential,
+							Description:  fmt.Sprintf("Retry policy - must be one of %s or %s", retryPolicySimple, retryPolicyExponential),
+							ValidateFunc: validation.StringInSlice([]string{retryPolicySimple, retryPolicyExponential}, false),
+						},
+						"max_attempts": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							Description:  "Maximum number of attempts of a statement, including the first one",
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"min_backoff": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      100,
+							Description:  "Minimum backoff between attempts in milliseconds, applies only to the exponential policy",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"max_backoff": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10000,
+							Description:  "Maximum backoff between attempts in milliseconds, applies only to the exponential policy",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
+			"local_dc": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the local datacenter, queries are routed to hosts of this datacenter first",
+			},
+			"token_aware": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Route queries to replicas owning the partition first",
+			},
+			"shuffle_replicas": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Shuffle replicas to spread load between them, applies only when token_aware is enabled",
+			},
+			"proxy": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "SOCKS5 proxy used for all connections to the cluster. When not set, the ALL_PROXY environment variable is honored",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							DefaultFunc:  schema.MultiEnvDefaultFunc([]string{"ALL_PROXY", "all_proxy"}, ""),
+							Description:  "Proxy URL, for example socks5://bastion:1080",
+							ValidateFunc: validateProxyURL,
+						},
+						"username": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Proxy username",
+						},
+						"password": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Proxy password",
+						},
+					},
+				},
+			},
+			"address_translation": &schema.Schema{
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Description:      "Map of private ip:port addresses advertised by nodes to public ip:port addresses used to connect to them",
+				ValidateDiagFunc: validateAddressTranslation,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"sigv4": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "AWS credentials used by the SigV4 authenticator when flavor is aws_keyspaces and username is not set. When not set, the AWS environment variables are honored",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							DefaultFunc: schema.MultiEnvDefaultFunc([]string{"AWS_REGION", "AWS_DEFAULT_REGION"}, ""),
+							Description: "AWS region of the Keyspaces endpoint",
+						},
+						"access_key": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							DefaultFunc: schema.EnvDefaultFunc("AWS_ACCESS_KEY_ID", ""),
+							Description: "AWS access key id",
+						},
+						"secret_key": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							DefaultFunc: schema.EnvDefaultFunc("AWS_SECRET_ACCESS_KEY", ""),
+							Description: "AWS secret access key",
+						},
+						"session_token": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							DefaultFunc: schema.EnvDefaultFunc("AWS_SESSION_TOKEN", ""),
+							Description: "AWS session token of temporary credentials",
+						},
+					},
+				},
+			},
+			"flavor": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      flavorAuto,
+				Description:  fmt.Sprintf("Database flavor used to pick system tables and quirks - must be one of %s, %s detects it from system.local", strings.Join(allowedFlavors, ", "), flavorAuto),
+				ValidateFunc: validation.StringInSlice(allowedFlavors, false),
+			},
+			"audit_log_path": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a file the statements executed by the resources are appended to as JSON lines, passwords are redacted",
+			},
+			"max_ddl_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of schema changes per second, schema changes always run one at a time, 0 disables the rate limit",
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"render_only_dir": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Directory the statements of the resources are written to as ordered .cql files instead of being executed, the cluster is never contacted",
+				ConflictsWith: []string{"preflight"},
+			},
+			"preflight": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Connect when the provider is configured and check the credentials, the cluster version and the permissions of the login role",
+			},
 			"disable_initial_host_lookup": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -172,19 +506,61 @@ func Provider() *schema.Provider {
 
 func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 
-	log.Printf("Creating provider")
+	tflog.Debug(ctx, "Creating provider")
+
+	if unknown := unknownAttributes(d.GetRawConfig()); len(unknown) > 0 {
+		return newDeferredClient(ctx, d, unknown), nil
+	}
 
 	useSSL := d.Get("use_ssl").(bool)
 	username := d.Get("username").(string)
 	password := d.Get("password").(string)
 	port := d.Get("port").(int)
 	connectionTimeout := d.Get("connection_timeout").(int)
-	protocolVersion := d.Get("protocol_version").(int)
+	protocolVersion := d.Get("protocol_version").(string)
 	diags := diag.Diagnostics{}
 
-	log.Printf("Using port %d", port)
-	log.Printf("Using use_ssl %v", useSSL)
-	log.Printf("Using username %s", username)
+	if v, ok := d.GetOk("password_file"); ok {
+		filePassword, err := readPasswordFile(v.(string))
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to read password file",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "password_file"}},
+			})
+			return nil, diags
+		}
+
+		password = filePassword
+	}
+
+	if v, ok := d.GetOk("credential_process"); ok {
+		credentials, err := runCredentialProcess(ctx, v.(string))
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to get credentials from credential process",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "credential_process"}},
+			})
+			return nil, diags
+		}
+
+		if credentials.Username != "" {
+			username = credentials.Username
+		}
+
+		password = credentials.Password
+	}
+
+	tflog.Debug(ctx, "Using connection settings", map[string]interface{}{
+		"port":     port,
+		"use_ssl":  useSSL,
+		"username": username,
+	})
 
 	var rawHosts []interface{}
 
@@ -194,13 +570,15 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 		rawHosts = d.Get("hosts").([]interface{})
 	}
 
-	hosts := make([]string, len(rawHosts))
+	hosts := make([]string, 0, len(rawHosts))
 	hostFilter := d.Get("host_filter").(bool)
 
 	for _, value := range rawHosts {
 		hosts = append(hosts, value.(string))
 
-		log.Printf("Using host %v", value.(string))
+		tflog.Debug(ctx, "Using host", map[string]interface{}{
+			"host": value.(string),
+		})
 	}
 
 	cluster := gocql.NewCluster()
@@ -209,14 +587,60 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 
 	cluster.Port = port
 
-	cluster.Authenticator = &gocql.PasswordAuthenticator{
-		Username: username,
-		Password: password,
+	if d.Get("flavor").(string) == flavorAWSKeyspaces && username == "" {
+		var credentials sigV4Credentials
+
+		if v, ok := d.GetOk("sigv4"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+			sigV4Config := v.([]interface{})[0].(map[string]interface{})
+
+			credentials = sigV4Credentials{
+				Region:       sigV4Config["region"].(string),
+				AccessKey:    sigV4Config["access_key"].(string),
+				SecretKey:    sigV4Config["secret_key"].(string),
+				SessionToken: sigV4Config["session_token"].(string),
+			}
+		}
+
+		authenticator, err := newSigV4Authenticator(credentials)
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid SigV4 configuration",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "sigv4"}},
+			})
+			return nil, diags
+		}
+
+		tflog.Debug(ctx, "Using SigV4 authentication", map[string]interface{}{
+			"access_key": authenticator.credentials.AccessKey,
+			"region":     authenticator.credentials.Region,
+		})
+
+		cluster.Authenticator = authenticator
+	} else {
+		cluster.Authenticator = &gocql.PasswordAuthenticator{
+			Username: username,
+			Password: password,
+		}
 	}
 
 	cluster.ConnectTimeout = time.Millisecond * time.Duration(connectionTimeout)
 
-	cluster.Timeout = time.Minute * time.Duration(1)
+	cluster.Timeout = time.Millisecond * time.Duration(d.Get("request_timeout").(int))
+
+	cluster.MaxWaitSchemaAgreement = time.Millisecond * time.Duration(d.Get("schema_agreement_timeout").(int))
+
+	cluster.Compressor = buildCompressor(d.Get("compression").(string))
+
+	cluster.NumConns = d.Get("num_conns").(int)
+
+	keepalive, keepaliveSet := d.GetOkExists("keepalive")
+
+	cluster.SocketKeepalive = time.Millisecond * time.Duration(keepalive.(int))
+
+	cluster.ReconnectInterval = time.Millisecond * time.Duration(d.Get("reconnect_interval").(int))
 
 	cluster.CQLVersion = d.Get("cql_version").(string)
 
@@ -226,23 +650,137 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 
 	cluster.Consistency = allowedConsistencies[d.Get("consistency").(string)]
 
-	cluster.ProtoVersion = protocolVersion
+	if v, ok := d.GetOk("serial_consistency"); ok {
+		cluster.SerialConsistency = allowedSerialConsistencies[v.(string)]
+	}
 
-	if hostFilter {
-		cluster.HostFilter = gocql.WhiteListHostFilter(hosts...)
+	if protocolVersion == protocolVersionAuto {
+		// negotiated by gocql when the session is created
+		cluster.ProtoVersion = 0
+	} else {
+		cluster.ProtoVersion, _ = strconv.Atoi(protocolVersion)
 	}
 
 	if v, ok := d.GetOkExists("disable_initial_host_lookup"); ok {
 		cluster.DisableInitialHostLookup = v.(bool)
 	}
 
+	if v, ok := d.GetOk("address_translation"); ok {
+		addressTranslator, err := buildAddressTranslator(ctx, v.(map[string]interface{}))
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid address translation",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "address_translation"}},
+			})
+			return nil, diags
+		}
+
+		cluster.AddressTranslator = addressTranslator
+	}
+
+	localDC := d.Get("local_dc").(string)
+	tokenAware := d.Get("token_aware").(bool)
+	shuffleReplicas := d.Get("shuffle_replicas").(bool)
+
+	if shuffleReplicas && !tokenAware {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "shuffle_replicas requires token_aware",
+			AttributePath: cty.Path{cty.GetAttrStep{Name: "shuffle_replicas"}},
+		})
+		return nil, diags
+	}
+
+	cluster.PoolConfig.HostSelectionPolicy = buildHostSelectionPolicy(localDC, tokenAware, shuffleReplicas)
+
+	if v, ok := d.GetOk("retry"); ok {
+		retryPolicy, err := buildRetryPolicy(v.([]interface{}))
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid retry policy",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "retry"}},
+			})
+			return nil, diags
+		}
+
+		cluster.RetryPolicy = retryPolicy
+	}
+
+	// only an explicit 0 disables keepalive, net.Dialer and the dialer of gocql use the Go default when it is 0
+	disableKeepalive := keepaliveSet && cluster.SocketKeepalive == 0
+
+	forwardDialer := &net.Dialer{
+		Timeout:   cluster.ConnectTimeout,
+		KeepAlive: cluster.SocketKeepalive,
+	}
+
+	if disableKeepalive {
+		forwardDialer.KeepAlive = -1
+	}
+
+	var (
+		proxyDialer gocql.Dialer
+		proxyError  error
+	)
+
+	if v, ok := d.GetOk("proxy"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		proxyConfig := v.([]interface{})[0].(map[string]interface{})
+		proxyURL := proxyConfig["url"].(string)
+
+		if proxyURL == "" {
+			proxyError = fmt.Errorf("proxy url must be set, either in the proxy block or in the ALL_PROXY environment variable")
+		} else {
+			proxyDialer, proxyError = buildProxyDialer(proxyURL, proxyConfig["username"].(string), proxyConfig["password"].(string), forwardDialer)
+		}
+	} else {
+		proxyDialer, proxyError = environmentProxyDialer(forwardDialer)
+	}
+
+	if proxyError != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Unable to configure proxy",
+			Detail:        proxyError.Error(),
+			AttributePath: cty.Path{cty.GetAttrStep{Name: "proxy"}},
+		})
+		return nil, diags
+	}
+
+	if proxyDialer != nil {
+		tflog.Debug(ctx, "Using proxy dialer")
+
+		cluster.Dialer = proxyDialer
+	} else if disableKeepalive {
+		cluster.Dialer = forwardDialer
+	}
+
 	if useSSL {
 
-		rootCA := d.Get("root_ca").(string)
 		minTLSVersion := d.Get("min_tls_version").(string)
+		hostVerification := d.Get("host_verification").(bool)
 
 		tlsConfig := &tls.Config{
-			MinVersion: allowedTLSProtocols[minTLSVersion],
+			MinVersion:         allowedTLSProtocols[minTLSVersion],
+			ServerName:         d.Get("tls_server_name").(string),
+			InsecureSkipVerify: !hostVerification,
+		}
+
+		rootCA, rootCAPath, err := readPEM(d, "root_ca", "root_ca_file")
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to read rootCA",
+				Detail:        err.Error(),
+				AttributePath: rootCAPath,
+			})
+			return nil, diags
 		}
 
 		if rootCA != "" {
@@ -253,7 +791,7 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 				diags = append(diags, diag.Diagnostic{
 					Severity:      diag.Error,
 					Summary:       "Unable to load rootCA",
-					AttributePath: cty.Path{cty.GetAttrStep{Name: "root_ca"}},
+					AttributePath: rootCAPath,
 				})
 				return nil, diags
 			}
@@ -261,10 +799,260 @@ func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}
 			tlsConfig.RootCAs = caPool
 		}
 
+		if !hostVerification {
+			// InsecureSkipVerify disables the whole verification, the chain is verified without the hostname instead
+			tlsConfig.VerifyPeerCertificate = verifyCertificateChain(tlsConfig.RootCAs)
+		}
+
+		clientCert, clientCertPath, err := readPEM(d, "client_cert", "client_cert_file")
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to read client certificate",
+				Detail:        err.Error(),
+				AttributePath: clientCertPath,
+			})
+			return nil, diags
+		}
+
+		clientKey, clientKeyPath, err := readPEM(d, "client_key", "client_key_file")
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to read client key",
+				Detail:        err.Error(),
+				AttributePath: clientKeyPath,
+			})
+			return nil, diags
+		}
+
+		if clientCert != "" && clientKey != "" {
+			certificate, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       "Unable to load client certificate",
+					Detail:        err.Error(),
+					AttributePath: clientCertPath,
+				})
+				return nil, diags
+			}
+
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+
 		cluster.SslOpts = &gocql.SslOptions{
-			Config: tlsConfig,
+			Config:                 tlsConfig,
+			EnableHostVerification: hostVerification,
+		}
+	}
+
+	if v, ok := d.GetOk("secure_connect_bundle"); ok {
+		bundle, err := applySecureConnectBundle(ctx, cluster, v.(string), allowedTLSProtocols[d.Get("min_tls_version").(string)])
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to load secure connect bundle",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "secure_connect_bundle"}},
+			})
+			return nil, diags
+		}
+
+		// local_dc and keyspace of the provider take precedence over the ones of the bundle
+		if localDC == "" && bundle.LocalDC != "" {
+			localDC = bundle.LocalDC
+			cluster.PoolConfig.HostSelectionPolicy = buildHostSelectionPolicy(localDC, tokenAware, shuffleReplicas)
+		}
+
+		if cluster.Keyspace == "" {
+			cluster.Keyspace = bundle.Keyspace
+		}
+	}
+
+	// built once the hosts are known, the secure connect bundle replaces them
+	if hostFilter {
+		cluster.HostFilter = gocql.WhiteListHostFilter(cluster.Hosts...)
+	}
+
+	client := NewClient(cluster)
+	client.flavor = d.Get("flavor").(string)
+	client.logContext = ctx
+
+	if maxDDLPerSecond := d.Get("max_ddl_per_second").(float64); maxDDLPerSecond > 0 {
+		client.minDDLInterval = time.Duration(float64(time.Second) / maxDDLPerSecond)
+	}
+
+	if auditLogPath := d.Get("audit_log_path").(string); auditLogPath != "" {
+		audit, err := openAuditLog(auditLogPath)
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to open audit log",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "audit_log_path"}},
+			})
+			return nil, diags
+		}
+
+		tflog.Debug(ctx, "Using audit log", map[string]interface{}{
+			"path": auditLogPath,
+		})
+
+		client.audit = audit
+	}
+
+	if renderOnlyDir := d.Get("render_only_dir").(string); renderOnlyDir != "" {
+		renderer, err := newRenderer(renderOnlyDir)
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Unable to use render directory",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "render_only_dir"}},
+			})
+			return nil, diags
+		}
+
+		tflog.Info(ctx, "Rendering statements instead of executing them", map[string]interface{}{
+			"path": renderOnlyDir,
+		})
+
+		client.renderer = renderer
+	}
+
+	if d.Get("preflight").(bool) {
+		report, err := client.preflight(ctx, username)
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Preflight check failed",
+				Detail:   err.Error(),
+			})
+			return nil, diags
+		}
+
+		tflog.Info(ctx, "Preflight check succeeded", map[string]interface{}{
+			"report": report.String(),
+		})
+	}
+
+	if localDC != "" && !client.renderOnly() {
+		tflog.Debug(ctx, "Using local_dc", map[string]interface{}{
+			"local_dc": localDC,
+		})
+
+		if err := client.checkDatacenter(ctx, localDC); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid local datacenter",
+				Detail:        err.Error(),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "local_dc"}},
+			})
+			return nil, diags
+		}
+	}
+
+	return client, diags
+}
+
+// readPEM returns the PEM content set inline or read from the file path attribute, along with the attribute it came from
+func readPEM(d *schema.ResourceData, inlineKey string, fileKey string) (string, cty.Path, error) {
+	if filePath := d.Get(fileKey).(string); filePath != "" {
+		content, err := ioutil.ReadFile(filePath)
+
+		if err != nil {
+			return "", cty.Path{cty.GetAttrStep{Name: fileKey}}, err
 		}
+
+		return string(content), cty.Path{cty.GetAttrStep{Name: fileKey}}, nil
+	}
+
+	return d.Get(inlineKey).(string), cty.Path{cty.GetAttrStep{Name: inlineKey}}, nil
+}
+
+// verifyCertificateChain returns a function verifying the certificate chain of the cluster against the root CAs,
+// the system roots when nil, without checking the hostname
+func verifyCertificateChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("the cluster did not send a certificate")
+		}
+
+		certificates := make([]*x509.Certificate, 0, len(rawCerts))
+
+		for _, rawCert := range rawCerts {
+			certificate, err := x509.ParseCertificate(rawCert)
+
+			if err != nil {
+				return fmt.Errorf("cannot parse the certificate of the cluster: %w", err)
+			}
+
+			certificates = append(certificates, certificate)
+		}
+
+		intermediates := x509.NewCertPool()
+
+		for _, certificate := range certificates[1:] {
+			intermediates.AddCert(certificate)
+		}
+
+		_, err := certificates[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+
+		return err
+	}
+}
+
+// buildRetryPolicy returns the gocql retry policy described by the retry block
+func buildRetryPolicy(raw []interface{}) (gocql.RetryPolicy, error) {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil, nil
+	}
+
+	retry := raw[0].(map[string]interface{})
+	numRetries := retry["max_attempts"].(int) - 1
+	minBackoff := time.Millisecond * time.Duration(retry["min_backoff"].(int))
+	maxBackoff := time.Millisecond * time.Duration(retry["max_backoff"].(int))
+
+	if retry["policy"].(string) == retryPolicySimple {
+		return &gocql.SimpleRetryPolicy{NumRetries: numRetries}, nil
+	}
+
+	if minBackoff > maxBackoff {
+		return nil, fmt.Errorf("min_backoff (%s) must not be greater than max_backoff (%s)", minBackoff, maxBackoff)
+	}
+
+	return &gocql.ExponentialBackoffRetryPolicy{
+		NumRetries: numRetries,
+		Min:        minBackoff,
+		Max:        maxBackoff,
+	}, nil
+}
+
+// buildHostSelectionPolicy returns the gocql host selection policy for the datacenter and token awareness settings
+func buildHostSelectionPolicy(localDC string, tokenAware bool, shuffleReplicas bool) gocql.HostSelectionPolicy {
+	var policy gocql.HostSelectionPolicy
+
+	if localDC != "" {
+		policy = gocql.DCAwareRoundRobinPolicy(localDC)
+	} else {
+		policy = gocql.RoundRobinHostPolicy()
+	}
+
+	if !tokenAware {
+		return policy
+	}
+
+	if shuffleReplicas {
+		return gocql.TokenAwareHostPolicy(policy, gocql.ShuffleReplicas())
 	}
 
-	return cluster, diags
+	return gocql.TokenAwareHostPolicy(policy)
 }
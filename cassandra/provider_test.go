@@ -2,10 +2,24 @@ package cassandra
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
 	"log"
+	"math/big"
+	"net"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/gocql/gocql"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -35,6 +49,25 @@ func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
+func TestProvider_resourceTimeouts(t *testing.T) {
+	for name, resource := range Provider().ResourcesMap {
+		timeouts, ok := resource.CoreConfigSchema().BlockTypes["timeouts"]
+		if !ok {
+			t.Fatalf("expected %s to have a timeouts block", name)
+		}
+
+		var keys []string
+		for key := range timeouts.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if !reflect.DeepEqual(keys, []string{"create", "delete", "read", "update"}) {
+			t.Fatalf("unexpected timeouts of %s: %v", name, keys)
+		}
+	}
+}
+
 func TestProvider_configure1(t *testing.T) {
 	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
 		"username": "cassanrda",
@@ -71,6 +104,340 @@ func TestProvider_configure2(t *testing.T) {
 	}
 }
 
+func TestProvider_configureClientCertificate(t *testing.T) {
+	cert, key := testGenerateCertificate(t)
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":        "asdf",
+		"use_ssl":     true,
+		"client_cert": cert,
+		"client_key":  key,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	err := p.Configure(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := p.Meta().(*Client).Cluster()
+	if len(cluster.SslOpts.Config.Certificates) != 1 {
+		t.Fatalf("expected 1 client certificate, got %d", len(cluster.SslOpts.Config.Certificates))
+	}
+}
+
+func TestProvider_configureInvalidClientKey(t *testing.T) {
+	cert, _ := testGenerateCertificate(t)
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":        "asdf",
+		"use_ssl":     true,
+		"client_cert": cert,
+		"client_key":  "not a key",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected invalid client_key to fail validation")
+	}
+}
+
+func TestProvider_configureCertificateFiles(t *testing.T) {
+	cert, key := testGenerateCertificate(t)
+	dir := t.TempDir()
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	if err := ioutil.WriteFile(certFile, []byte(cert), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(keyFile, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":              "asdf",
+		"use_ssl":           true,
+		"root_ca_file":      certFile,
+		"client_cert_file":  certFile,
+		"client_key_file":   keyFile,
+		"tls_server_name":   "cassandra.example.com",
+		"host_verification": false,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	err := p.Configure(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sslOpts := p.Meta().(*Client).Cluster().SslOpts
+	if sslOpts.Config.RootCAs == nil {
+		t.Fatal("expected root CA to be loaded")
+	}
+	if len(sslOpts.Config.Certificates) != 1 {
+		t.Fatalf("expected 1 client certificate, got %d", len(sslOpts.Config.Certificates))
+	}
+	if sslOpts.Config.ServerName != "cassandra.example.com" {
+		t.Fatalf("unexpected server name %s", sslOpts.Config.ServerName)
+	}
+	if sslOpts.EnableHostVerification || !sslOpts.Config.InsecureSkipVerify {
+		t.Fatal("expected host verification to be disabled")
+	}
+	if sslOpts.Config.VerifyPeerCertificate == nil {
+		t.Fatal("expected the certificate chain to be verified")
+	}
+}
+
+func TestProvider_verifyCertificateChain(t *testing.T) {
+	cert, _ := testGenerateCertificate(t)
+	otherCert, _ := testGenerateCertificate(t)
+
+	block, _ := pem.Decode([]byte(cert))
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM([]byte(cert))
+
+	// the certificate is issued for localhost, the hostname is not checked
+	if err := verifyCertificateChain(roots)([][]byte{block.Bytes}, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	otherRoots := x509.NewCertPool()
+	otherRoots.AppendCertsFromPEM([]byte(otherCert))
+
+	if err := verifyCertificateChain(otherRoots)([][]byte{block.Bytes}, nil); err == nil {
+		t.Fatal("expected a certificate signed by another CA to be rejected")
+	}
+}
+
+func TestProvider_configureConflictingRootCA(t *testing.T) {
+	cert, _ := testGenerateCertificate(t)
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":         "asdf",
+		"use_ssl":      true,
+		"root_ca":      cert,
+		"root_ca_file": "/tmp/ca.pem",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected root_ca and root_ca_file to conflict")
+	}
+}
+
+func TestProvider_configureRetry(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"retry": []interface{}{
+			map[string]interface{}{
+				"max_attempts": 5,
+				"min_backoff":  50,
+				"max_backoff":  2000,
+			},
+		},
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	err := p.Configure(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retryPolicy, ok := p.Meta().(*Client).Cluster().RetryPolicy.(*gocql.ExponentialBackoffRetryPolicy)
+	if !ok {
+		t.Fatal("expected an exponential backoff retry policy")
+	}
+	if retryPolicy.NumRetries != 4 || retryPolicy.Min != 50*time.Millisecond || retryPolicy.Max != 2*time.Second {
+		t.Fatalf("unexpected retry policy %+v", retryPolicy)
+	}
+}
+
+func TestProvider_configureSimpleRetry(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"retry": []interface{}{
+			map[string]interface{}{
+				"policy":       "simple",
+				"max_attempts": 2,
+			},
+		},
+	})
+	p := Provider()
+	err := p.Configure(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retryPolicy, ok := p.Meta().(*Client).Cluster().RetryPolicy.(*gocql.SimpleRetryPolicy)
+	if !ok || retryPolicy.NumRetries != 1 {
+		t.Fatalf("unexpected retry policy %+v", p.Meta().(*Client).Cluster().RetryPolicy)
+	}
+}
+
+func TestProvider_configureShuffleReplicasWithoutTokenAware(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":             "asdf",
+		"shuffle_replicas": true,
+	})
+	err := Provider().Configure(context.Background(), rc)
+	if !err.HasError() {
+		t.Fatal("expected shuffle_replicas without token_aware to fail")
+	}
+}
+
+func TestProvider_configureAddressTranslation(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"address_translation": map[string]interface{}{
+			"10.0.0.1:9042": "203.0.113.10:19042",
+		},
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	translator := p.Meta().(*Client).Cluster().AddressTranslator
+
+	ip, port := translator.Translate(net.ParseIP("10.0.0.1"), 9042)
+	if ip.String() != "203.0.113.10" || port != 19042 {
+		t.Fatalf("unexpected translation %s:%d", ip, port)
+	}
+
+	ip, port = translator.Translate(net.ParseIP("10.0.0.2"), 9042)
+	if ip.String() != "10.0.0.2" || port != 9042 {
+		t.Fatalf("expected untranslated address, got %s:%d", ip, port)
+	}
+}
+
+func TestProvider_configureInvalidAddressTranslation(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"address_translation": map[string]interface{}{
+			"10.0.0.1": "public-host:9042",
+		},
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected invalid address translation to fail validation")
+	}
+}
+
+func TestProvider_configurePool(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":               "asdf",
+		"compression":        "lz4",
+		"num_conns":          4,
+		"keepalive":          30000,
+		"reconnect_interval": 10000,
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	cluster := p.Meta().(*Client).Cluster()
+	if cluster.Compressor.Name() != "lz4" || cluster.NumConns != 4 || cluster.SocketKeepalive != 30*time.Second || cluster.ReconnectInterval != 10*time.Second {
+		t.Fatalf("unexpected cluster configuration %+v", cluster)
+	}
+}
+
+func TestProvider_configureKeepalive(t *testing.T) {
+	t.Setenv("ALL_PROXY", "")
+	t.Setenv("all_proxy", "")
+
+	for _, tc := range []struct {
+		config   map[string]interface{}
+		disabled bool
+	}{
+		{map[string]interface{}{"host": "asdf"}, false},
+		{map[string]interface{}{"host": "asdf", "keepalive": 30000}, false},
+		{map[string]interface{}{"host": "asdf", "keepalive": 0}, true},
+	} {
+		p := Provider()
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(tc.config))
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+
+		dialer := p.Meta().(*Client).Cluster().Dialer
+
+		if !tc.disabled {
+			// the dialer of gocql keeps the configured or the Go default keepalive
+			if dialer != nil {
+				t.Fatalf("%v: expected the dialer of gocql, got %+v", tc.config, dialer)
+			}
+			continue
+		}
+
+		if netDialer, ok := dialer.(*net.Dialer); !ok || netDialer.KeepAlive >= 0 {
+			t.Fatalf("%v: expected keepalive to be disabled, got %+v", tc.config, dialer)
+		}
+	}
+}
+
+func TestProvider_configureInvalidCompression(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":        "asdf",
+		"compression": "gzip",
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected gzip compression to fail validation")
+	}
+}
+
+func testGenerateCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cassandra"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return string(cert), string(keyPem)
+}
+
 func testAccPreCheck(t *testing.T) {
 	url := os.Getenv("CASSANDRA_HOST")
 	if url == "" {
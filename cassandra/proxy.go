@@ -0,0 +1,77 @@
+package cassandra
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/gocql/gocql"
+	"golang.org/x/net/proxy"
+)
+
+var (
+	allowedProxySchemes = []string{"socks5", "socks5h"}
+)
+
+// buildProxyDialer returns a dialer connecting through the SOCKS5 proxy, credentials override the ones of the URL
+func buildProxyDialer(proxyURL string, username string, password string, forward *net.Dialer) (gocql.Dialer, error) {
+	u, err := url.Parse(proxyURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	if username != "" {
+		u.User = url.UserPassword(username, password)
+	}
+
+	dialer, err := proxy.FromURL(u, forward)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create proxy dialer: %w", err)
+	}
+
+	return toContextDialer(dialer)
+}
+
+// environmentProxyDialer returns a dialer honoring ALL_PROXY and NO_PROXY, or nil when no proxy is set in the environment
+func environmentProxyDialer(forward *net.Dialer) (gocql.Dialer, error) {
+	if os.Getenv("ALL_PROXY") == "" && os.Getenv("all_proxy") == "" {
+		return nil, nil
+	}
+
+	return toContextDialer(proxy.FromEnvironmentUsing(forward))
+}
+
+func toContextDialer(dialer proxy.Dialer) (gocql.Dialer, error) {
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+
+	if !ok {
+		return nil, fmt.Errorf("proxy dialer does not support contexts")
+	}
+
+	return contextDialer, nil
+}
+
+func validateProxyURL(i interface{}, key string) ([]string, []error) {
+	value := i.(string)
+
+	if value == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(value)
+
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid URL: %v", key, err)}
+	}
+
+	for _, scheme := range allowedProxySchemes {
+		if u.Scheme == scheme {
+			return nil, nil
+		}
+	}
+
+	return nil, []error{fmt.Errorf("%s: unsupported scheme %q, must be one of %v", key, u.Scheme, allowedProxySchemes)}
+}
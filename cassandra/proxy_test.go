@@ -0,0 +1,221 @@
+package cassandra
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_configureProxy(t *testing.T) {
+	target := testEchoServer(t)
+	socks := testSOCKS5Server(t, "bastion", "secret")
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"proxy": []interface{}{
+			map[string]interface{}{
+				"url":      fmt.Sprintf("socks5://%s", socks),
+				"username": "bastion",
+				"password": "secret",
+			},
+		},
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	dialer := p.Meta().(*Client).Cluster().Dialer
+	if dialer == nil {
+		t.Fatal("expected a proxy dialer")
+	}
+
+	conn, err := dialer.DialContext(context.Background(), "tcp", target)
+	if err != nil {
+		t.Fatalf("dial through proxy failed: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Fatalf("unexpected reply %q: %v", reply, err)
+	}
+}
+
+func TestProvider_configureProxyWrongCredentials(t *testing.T) {
+	target := testEchoServer(t)
+	socks := testSOCKS5Server(t, "bastion", "secret")
+
+	dialer, err := buildProxyDialer(fmt.Sprintf("socks5://%s", socks), "bastion", "wrong", &net.Dialer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dialer.DialContext(context.Background(), "tcp", target); err == nil {
+		t.Fatal("expected dial with wrong credentials to fail")
+	}
+}
+
+func TestProvider_configureProxyInvalidScheme(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host": "asdf",
+		"proxy": []interface{}{
+			map[string]interface{}{
+				"url": "http://proxy:3128",
+			},
+		},
+	})
+	v := Provider().Validate(rc)
+	if !v.HasError() {
+		t.Fatal("expected http proxy to fail validation")
+	}
+}
+
+func testEchoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// testSOCKS5Server starts a minimal SOCKS5 server supporting username/password authentication and CONNECT
+func testSOCKS5Server(t *testing.T, username string, password string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go testSOCKS5Handle(conn, username, password)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func testSOCKS5Handle(conn net.Conn, username string, password string) {
+	defer conn.Close()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+
+	conn.Write([]byte{0x05, 0x02})
+
+	// username/password sub-negotiation, RFC 1929
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return
+	}
+
+	passwordLength := make([]byte, 1)
+	if _, err := io.ReadFull(conn, passwordLength); err != nil {
+		return
+	}
+
+	pass := make([]byte, passwordLength[0])
+	if _, err := io.ReadFull(conn, pass); err != nil {
+		return
+	}
+
+	if string(user) != username || string(pass) != password {
+		conn.Write([]byte{0x01, 0x01})
+		return
+	}
+
+	conn.Write([]byte{0x01, 0x00})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+
+	var host string
+
+	switch request[3] {
+	case 0x01:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return
+		}
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	default:
+		return
+	}
+
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBytes); err != nil {
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes)))))
+	if err != nil {
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+
+	conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
@@ -0,0 +1,94 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	renderFileRegex     = regexp.MustCompile(`^(\d+)_.*\.cql$`)
+	unsafeFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// renderer writes statements to numbered .cql files instead of executing them,
+// numbering continues after the files already in the directory so that runs stay ordered
+type renderer struct {
+	dir      string
+	lock     sync.Mutex
+	sequence int
+}
+
+func newRenderer(dir string) (*renderer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("cannot create render directory: %w", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read render directory: %w", err)
+	}
+
+	r := &renderer{dir: dir}
+
+	for _, file := range files {
+		if match := renderFileRegex.FindStringSubmatch(file.Name()); match != nil {
+			if sequence, err := strconv.Atoi(match[1]); err == nil && sequence > r.sequence {
+				r.sequence = sequence
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// write renders the statement into the next file, named after the resource and operation of the context
+func (r *renderer) write(ctx context.Context, statement string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	resource, _ := ctx.Value(auditContextKey{}).(auditResource)
+
+	name := strings.Trim(unsafeFileNameRegex.ReplaceAllString(fmt.Sprintf("%s_%s", resource.Operation, resource.Address), "_"), "_")
+	path := filepath.Join(r.dir, fmt.Sprintf("%04d_%s.cql", r.sequence+1, name))
+	content := fmt.Sprintf("-- %s %s\n%s;\n", resource.Operation, resource.Address, statement)
+
+	// role statements hold passwords
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("cannot render statement: %w", err)
+	}
+
+	r.sequence++
+
+	return path, nil
+}
+
+// renderOnly returns whether statements are rendered to files instead of being executed
+func (c *Client) renderOnly() bool {
+	return c.renderer != nil
+}
+
+// render writes the statement to the render directory
+func (c *Client) render(ctx context.Context, statement string) error {
+	path, err := c.renderer.write(ctx, statement)
+
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, "Rendered statement", map[string]interface{}{
+		"path":                path,
+		logFieldStatementKind: statementKind(statement),
+	})
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package cassandra
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestRender_continuesNumbering(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "0007_create_cassandra_role.app.cql"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := newRenderer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := withResource(context.Background(), "cassandra_keyspace", "my keyspace", operationCreate)
+
+	path, err := r.write(ctx, "CREATE KEYSPACE x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Base(path) != "0008_create_cassandra_keyspace.my_keyspace.cql" {
+		t.Fatalf("unexpected file %s", path)
+	}
+}
+
+func TestRender_resources(t *testing.T) {
+	dir := t.TempDir()
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":            "asdf",
+		"render_only_dir": dir,
+	})
+	p := Provider()
+	if diags := p.Configure(context.Background(), rc); diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	keyspace := schema.TestResourceDataRaw(t, resourceCassandraKeyspace().Schema, map[string]interface{}{
+		"name":                 "app",
+		"replication_strategy": "SimpleStrategy",
+		"strategy_options": map[string]interface{}{
+			"replication_factor": "3",
+		},
+	})
+	if diags := resourceKeyspaceCreate(context.Background(), keyspace, p.Meta()); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if keyspace.Id() != "app" || keyspace.Get("replication_strategy") != "SimpleStrategy" {
+		t.Fatalf("expected state to reflect the configuration, got id %s", keyspace.Id())
+	}
+
+	role := schema.TestResourceDataRaw(t, resourceCassandraRole().Schema, map[string]interface{}{
+		"name":     "app",
+		"password": "a-password-of-20-chars",
+	})
+	if diags := resourceRoleCreate(context.Background(), role, p.Meta()); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags := resourceRoleRead(context.Background(), role, p.Meta()); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags := resourceKeyspaceDelete(context.Background(), keyspace, p.Meta()); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name      string
+		statement string
+	}{
+		{"0001_create_cassandra_keyspace.app.cql", "CREATE KEYSPACE app WITH REPLICATION = { 'class' : 'SimpleStrategy', 'replication_factor' : '3' } AND DURABLE_WRITES = true;"},
+		{"0002_create_cassandra_role.app.cql", "CREATE ROLE 'app' WITH PASSWORD = 'a-password-of-20-chars' AND LOGIN = true AND SUPERUSER = false;"},
+		{"0003_delete_cassandra_keyspace.app.cql", "DROP KEYSPACE app;"},
+	}
+
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(files))
+	}
+
+	for i, file := range files {
+		if file.Name() != expected[i].name {
+			t.Fatalf("expected file %s, got %s", expected[i].name, file.Name())
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(content), expected[i].statement) {
+			t.Fatalf("expected %s to contain %s, got %s", file.Name(), expected[i].statement, content)
+		}
+	}
+}
+
+func TestRender_conflictsWithPreflight(t *testing.T) {
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":            "asdf",
+		"render_only_dir": t.TempDir(),
+		"preflight":       true,
+	})
+	if v := Provider().Validate(rc); !v.HasError() {
+		t.Fatal("expected render_only_dir and preflight to conflict")
+	}
+}
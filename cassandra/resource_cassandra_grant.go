@@ -5,12 +5,12 @@ import (
 	"context"
 	"fmt"
 	"html/template"
-	"log"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/gocql/gocql"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -133,10 +133,17 @@ func validIdentifier(i interface{}, path cty.Path, identifierName string, regula
 
 func resourceCassandraGrant() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceGrantCreate,
-		ReadContext:   resourceGrantRead,
-		UpdateContext: resourceGrantUpdate,
-		DeleteContext: resourceGrantDelete,
+		CreateContext: withAuditWarnings(resourceGrantCreate),
+		ReadContext:   withAuditWarnings(resourceGrantRead),
+		UpdateContext: withAuditWarnings(resourceGrantUpdate),
+		DeleteContext: withAuditWarnings(resourceGrantDelete),
+		CustomizeDiff: resourceGrantCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			identifierPrivilege: &schema.Schema{
 				Type:        schema.TypeString,
@@ -278,6 +285,7 @@ func resourceCassandraGrant() *schema.Resource {
 				},
 				ConflictsWith: []string{identifierFunctionName, identifierTableName, identifierRoleName, identifierMbeanName, identifierKeyspaceName},
 			},
+			"consistency": resourceConsistencySchema(),
 		},
 	}
 }
@@ -338,23 +346,62 @@ func parseData(d *schema.ResourceData) (*Grant, error) {
 	return &Grant{privilege, resourceType, grantee, keyspaceName, identifier}, nil
 }
 
-func resourceGrantExists(d *schema.ResourceData, meta interface{}) (b bool, e error) {
+// resourceGrantCustomizeDiff rejects resource types the database flavor or version does not support at plan time
+func resourceGrantCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	resourceType := d.Get(identifierResourceType).(string)
+	flavor := meta.(*Client).knownFlavor()
+
+	if flavor != nil {
+		if err := flavor.supportsType("cassandra_grant"); err != nil {
+			return err
+		}
+	} else if flavorsLackResource(resourceType) && !meta.(*Client).offline() {
+		var err error
+
+		if flavor, err = meta.(*Client).Flavor(ctx); err != nil {
+			return err
+		}
+	}
+
+	if flavor != nil {
+		if err := flavor.supportsResource(resourceType); err != nil {
+			return err
+		}
+	}
+
+	if feature, ok := resourceTypeToFeature[resourceType]; ok {
+		return meta.(*Client).requireFeature(ctx, feature)
+	}
+
+	return nil
+}
+
+// withGrant adds the grantee and keyspace of the grant to the log fields
+func withGrant(ctx context.Context, grant *Grant) context.Context {
+	ctx = tflog.SetField(ctx, logFieldRole, grant.Grantee)
+
+	if grant.Keyspace != "" {
+		ctx = tflog.SetField(ctx, logFieldKeyspace, grant.Keyspace)
+	}
+
+	return ctx
+}
+
+func resourceGrantExists(ctx context.Context, d *schema.ResourceData, meta interface{}) (b bool, e error) {
 	grant, err := parseData(d)
 
 	if err != nil {
 		return false, err
 	}
 
-	cluster := meta.(*gocql.ClusterConfig)
+	ctx = withGrant(ctx, grant)
 
-	session, sessionCreationError := cluster.CreateSession()
+	session, sessionCreationError := meta.(*Client).Session(ctx)
 
 	if sessionCreationError != nil {
 		return false, sessionCreationError
 	}
 
-	defer session.Close()
-
 	var buffer bytes.Buffer
 	templateRenderError := templateRead.Execute(&buffer, grant)
 
@@ -364,7 +411,8 @@ func resourceGrantExists(d *schema.ResourceData, meta interface{}) (b bool, e er
 
 	query := buffer.String()
 
-	iter := session.Query(query).Iter()
+	// Scylla accepts the same LIST statement and returns the same columns, only the presence of a row is checked
+	iter := meta.(*Client).query(ctx, session, query).Iter()
 
 	rowCount := iter.NumRows()
 
@@ -385,14 +433,8 @@ func resourceGrantCreate(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.FromErr(err)
 	}
 
-	cluster := meta.(*gocql.ClusterConfig)
-	session, sessionCreationError := cluster.CreateSession()
-
-	if sessionCreationError != nil {
-		return diag.FromErr(sessionCreationError)
-	}
-
-	defer session.Close()
+	ctx = withGrant(withResource(ctx, "cassandra_grant", hash(fmt.Sprintf("%+v", grant)), operationCreate), grant)
+	ctx = withConsistency(ctx, d)
 
 	var buffer bytes.Buffer
 
@@ -404,14 +446,32 @@ func resourceGrantCreate(ctx context.Context, d *schema.ResourceData, meta inter
 
 	query := buffer.String()
 
-	log.Printf("Executing query %v", query)
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		d.SetId(hash(fmt.Sprintf("%+v", grant)))
 
-	err = session.Query(query).Exec()
+		return diags
+	}
+
+	session, sessionCreationError := meta.(*Client).Session(ctx)
+
+	if sessionCreationError != nil {
+		return diag.FromErr(sessionCreationError)
+	}
+
+	start := time.Now()
+
+	err = meta.(*Client).query(ctx, session, query).Exec()
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	diags = append(diags, meta.(*Client).awaitSchemaAgreement(ctx, session, start)...)
+
 	d.SetId(hash(fmt.Sprintf("%+v", grant)))
 
 	diags = append(diags, resourceGrantRead(ctx, d, meta)...)
@@ -420,7 +480,15 @@ func resourceGrantCreate(ctx context.Context, d *schema.ResourceData, meta inter
 }
 
 func resourceGrantRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	exists, err := resourceGrantExists(d, meta)
+	if meta.(*Client).offline() {
+		// nothing is read back, the state keeps the configuration
+		return nil
+	}
+
+	ctx = withResource(ctx, "cassandra_grant", d.Id(), operationRead)
+	ctx = withConsistency(ctx, d)
+
+	exists, err := resourceGrantExists(ctx, d, meta)
 	var diags diag.Diagnostics
 
 	if err != nil {
@@ -462,6 +530,9 @@ func resourceGrantDelete(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.FromErr(err)
 	}
 
+	ctx = withGrant(withResource(ctx, "cassandra_grant", d.Id(), operationDelete), grant)
+	ctx = withConsistency(ctx, d)
+
 	var buffer bytes.Buffer
 
 	err = templateDelete.Execute(&buffer, grant)
@@ -470,26 +541,38 @@ func resourceGrantDelete(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.FromErr(err)
 	}
 
-	cluster := meta.(*gocql.ClusterConfig)
+	query := buffer.String()
 
-	session, err := cluster.CreateSession()
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		return diags
+	}
+
+	session, err := meta.(*Client).Session(ctx)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	query := buffer.String()
-
-	defer session.Close()
+	start := time.Now()
 
-	err = session.Query(query).Exec()
+	err = meta.(*Client).query(ctx, session, query).Exec()
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	diags = append(diags, meta.(*Client).awaitSchemaAgreement(ctx, session, start)...)
+
 	return diags
 }
 
 func resourceGrantUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if onlyConsistencyChanged(d) {
+		return nil
+	}
+
 	return diag.Errorf("Updating of grants is not supported")
 }
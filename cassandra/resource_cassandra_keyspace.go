@@ -3,7 +3,6 @@ package cassandra
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"sort"
 	"strings"
@@ -30,10 +29,17 @@ var (
 
 func resourceCassandraKeyspace() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceKeyspaceCreate,
-		ReadContext:   resourceKeyspaceRead,
-		UpdateContext: resourceKeyspaceUpdate,
-		DeleteContext: resourceKeyspaceDelete,
+		CreateContext: withAuditWarnings(resourceKeyspaceCreate),
+		ReadContext:   withAuditWarnings(resourceKeyspaceRead),
+		UpdateContext: withAuditWarnings(resourceKeyspaceUpdate),
+		DeleteContext: withAuditWarnings(resourceKeyspaceDelete),
+		CustomizeDiff: resourceKeyspaceCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -75,14 +81,14 @@ func resourceCassandraKeyspace() *schema.Resource {
 				Type:         schema.TypeString,
 				Required:     true,
 				ForceNew:     false,
-				Description:  "Keyspace replication strategy - must be one of SimpleStrategy or NetworkTopologyStrategy",
+				Description:  "Keyspace replication strategy - must be one of SimpleStrategy, NetworkTopologyStrategy or SingleRegionStrategy",
 				ValidateFunc: validation.StringInSlice([]string{"SimpleStrategy", "NetworkTopologyStrategy", "SingleRegionStrategy"}, false),
 			},
 			"strategy_options": &schema.Schema{
 				Type:        schema.TypeMap,
-				Required:    true,
+				Optional:    true,
 				ForceNew:    false,
-				Description: "strategy options used with replication strategy",
+				Description: "strategy options used with replication strategy, required unless the strategy is SingleRegionStrategy",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -110,15 +116,37 @@ func resourceCassandraKeyspace() *schema.Resource {
 				Description: "Enable or disable durable writes - disabling is not recommended",
 				Default:     true,
 			},
+			"consistency": resourceConsistencySchema(),
 		},
 	}
 }
 
+// resourceKeyspaceCustomizeDiff rejects replication settings the flavor or version does not support at plan time
+func resourceKeyspaceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	flavor := meta.(*Client).knownFlavor()
+	replicationStrategy := d.Get("replication_strategy").(string)
+
+	if flavor != nil && replicationStrategy != "" {
+		if err := flavor.supportsReplicationStrategy(replicationStrategy); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range d.Get("strategy_options").(map[string]interface{}) {
+		// transient replicas are written as a replication factor such as 3/1
+		if strings.Contains(value.(string), "/") {
+			return meta.(*Client).requireFeature(ctx, featureTransientReplication)
+		}
+	}
+
+	return nil
+}
+
 func generateCreateOrUpdateKeyspaceQueryString(name string, create bool, replicationStrategy string, strategyOptions map[string]interface{}, durableWrites bool) (string, error) {
 
 	numberOfStrategyOptions := len(strategyOptions)
 
-	if numberOfStrategyOptions == 0 {
+	if numberOfStrategyOptions == 0 && replicationStrategy != "SingleRegionStrategy" {
 		return "", fmt.Errorf("must specify stratgey options - see https://docs.datastax.com/en/cql/3.3/cql/cql_reference/cqlCreateKeyspace.html")
 	}
 
@@ -130,8 +158,6 @@ func generateCreateOrUpdateKeyspaceQueryString(name string, create bool, replica
 
 	query += fmt.Sprintf(` } AND DURABLE_WRITES = %t`, durableWrites)
 
-	log.Println("query", query)
-
 	return query, nil
 }
 
@@ -142,29 +168,35 @@ func resourceKeyspaceCreate(ctx context.Context, d *schema.ResourceData, meta in
 	durableWrites := d.Get("durable_writes").(bool)
 	var diags diag.Diagnostics
 
+	ctx = withResource(ctx, "cassandra_keyspace", name, operationCreate)
+	ctx = withConsistency(ctx, d)
+
 	query, err := generateCreateOrUpdateKeyspaceQueryString(name, true, replicationStrategy, strategyOptions, durableWrites)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	cluster := meta.(*gocql.ClusterConfig)
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
 
-	log.Printf("Getting a session took %s", elapsed)
+		d.SetId(name)
+
+		return diags
+	}
+
+	session, sessionCreateError := meta.(*Client).Session(ctx)
 
 	if sessionCreateError != nil {
 		return diag.FromErr(sessionCreateError)
 	}
 
-	defer session.Close()
-
-	err = session.Query(query).Exec()
+	diags = append(diags, meta.(*Client).executeSchemaChange(ctx, session, query)...)
 
-	if err != nil {
-		return diag.FromErr(err)
+	if diags.HasError() {
+		return diags
 	}
 
 	d.SetId(name)
@@ -176,22 +208,17 @@ func resourceKeyspaceCreate(ctx context.Context, d *schema.ResourceData, meta in
 
 func resourceKeyspaceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Id()
-	cluster := meta.(*gocql.ClusterConfig)
 	var diags diag.Diagnostics
 
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
-
-	log.Printf("Getting a session took %s", elapsed)
-
-	if sessionCreateError != nil {
-		return diag.FromErr(sessionCreateError)
+	if meta.(*Client).offline() {
+		// nothing is read back, the state keeps the configuration
+		return diags
 	}
 
-	defer session.Close()
+	ctx = withResource(ctx, "cassandra_keyspace", name, operationRead)
+	ctx = withConsistency(ctx, d)
 
-	keyspaceMetadata, err := session.KeyspaceMetadata(name)
+	keyspaceMetadata, err := readKeyspace(ctx, meta.(*Client), name)
 
 	if err == gocql.ErrKeyspaceDoesNotExist {
 		d.SetId("")
@@ -203,7 +230,7 @@ func resourceKeyspaceRead(ctx context.Context, d *schema.ResourceData, meta inte
 	strategyOptions := make(map[string]string)
 
 	for key, value := range keyspaceMetadata.StrategyOptions {
-		strategyOptions[key] = value.(string)
+		strategyOptions[key] = fmt.Sprint(value)
 	}
 
 	strategyClass := strings.TrimPrefix(keyspaceMetadata.StrategyClass, "org.apache.cassandra.locator.")
@@ -216,28 +243,100 @@ func resourceKeyspaceRead(ctx context.Context, d *schema.ResourceData, meta inte
 	return diags
 }
 
+// keyspaceMetadata reads the keyspace from the driver schema metadata, returning early when the context is cancelled
+func keyspaceMetadata(ctx context.Context, session *gocql.Session, name string) (*gocql.KeyspaceMetadata, error) {
+	type result struct {
+		keyspaceMetadata *gocql.KeyspaceMetadata
+		err              error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		keyspaceMetadata, err := session.KeyspaceMetadata(name)
+		done <- result{keyspaceMetadata, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.keyspaceMetadata, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readKeyspace returns the keyspace metadata, from the flavor keyspaces table when it has one or from the driver otherwise
+func readKeyspace(ctx context.Context, client *Client, name string) (*gocql.KeyspaceMetadata, error) {
+	session, err := client.Session(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	flavor, err := client.Flavor(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if flavor.keyspacesTable == "" {
+		return keyspaceMetadata(ctx, session, name)
+	}
+
+	var (
+		durableWrites bool
+		replication   map[string]string
+	)
+
+	err = client.query(ctx, session, fmt.Sprintf(`SELECT durable_writes, replication FROM %s WHERE keyspace_name = ?`, flavor.keyspacesTable), name).Scan(&durableWrites, &replication)
+
+	if err == gocql.ErrNotFound {
+		return nil, gocql.ErrKeyspaceDoesNotExist
+	} else if err != nil {
+		return nil, err
+	}
+
+	keyspaceMetadata := &gocql.KeyspaceMetadata{
+		Name:            name,
+		DurableWrites:   durableWrites,
+		StrategyClass:   replication["class"],
+		StrategyOptions: make(map[string]interface{}),
+	}
+
+	for key, value := range replication {
+		if key != "class" {
+			keyspaceMetadata.StrategyOptions[key] = value
+		}
+	}
+
+	return keyspaceMetadata, nil
+}
+
 func resourceKeyspaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Get("name").(string)
-	cluster := meta.(*gocql.ClusterConfig)
 	var diags diag.Diagnostics
 
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
+	ctx = withResource(ctx, "cassandra_keyspace", name, operationDelete)
+	ctx = withConsistency(ctx, d)
 
-	log.Printf("Getting a session took %s", elapsed)
+	query := fmt.Sprintf(`DROP KEYSPACE %s`, name)
 
-	if sessionCreateError != nil {
-		return diag.FromErr(sessionCreateError)
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		return diags
 	}
 
-	defer session.Close()
+	session, sessionCreateError := meta.(*Client).Session(ctx)
 
-	err := session.Query(fmt.Sprintf(`DROP KEYSPACE %s`, name)).Exec()
-	if err != nil {
-		return diag.FromErr(err)
+	if sessionCreateError != nil {
+		return diag.FromErr(sessionCreateError)
 	}
 
+	diags = append(diags, meta.(*Client).executeSchemaChange(ctx, session, query)...)
+
 	return diags
 }
 
@@ -248,29 +347,37 @@ func resourceKeyspaceUpdate(ctx context.Context, d *schema.ResourceData, meta in
 	durableWrites := d.Get("durable_writes").(bool)
 	var diags diag.Diagnostics
 
+	ctx = withResource(ctx, "cassandra_keyspace", name, operationUpdate)
+	ctx = withConsistency(ctx, d)
+
+	if onlyConsistencyChanged(d) {
+		return diags
+	}
+
 	query, err := generateCreateOrUpdateKeyspaceQueryString(name, false, replicationStrategy, strategyOptions, durableWrites)
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	cluster := meta.(*gocql.ClusterConfig)
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		return diags
+	}
 
-	log.Printf("Getting a session took %s", elapsed)
+	session, sessionCreateError := meta.(*Client).Session(ctx)
 
 	if sessionCreateError != nil {
 		return diag.FromErr(sessionCreateError)
 	}
 
-	defer session.Close()
-
-	err = session.Query(query).Exec()
+	diags = append(diags, meta.(*Client).executeSchemaChange(ctx, session, query)...)
 
-	if err != nil {
-		return diag.FromErr(err)
+	if diags.HasError() {
+		return diags
 	}
 
 	diags = append(diags, resourceKeyspaceRead(ctx, d, meta)...)
@@ -1,6 +1,7 @@
 package cassandra
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"testing"
@@ -78,15 +79,12 @@ resource "cassandra_keyspace" "keyspace" {
 }
 
 func testAccCassandraKeyspaceDestroy(s *terraform.State) error {
-	cluster := testAccProvider.Meta().(*gocql.ClusterConfig)
-	session, sessionCreateError := cluster.CreateSession()
+	session, sessionCreateError := testAccProvider.Meta().(*Client).Session(context.Background())
 
 	if sessionCreateError != nil {
 		return sessionCreateError
 	}
 
-	defer session.Close()
-
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cassandra_keyspace" {
 			continue
@@ -117,9 +115,7 @@ func testAccCassandraKeyspaceExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		cluster := testAccProvider.Meta().(*gocql.ClusterConfig)
-
-		session, sessionCreateError := cluster.CreateSession()
+		session, sessionCreateError := testAccProvider.Meta().(*Client).Session(context.Background())
 
 		if sessionCreateError != nil {
 			return sessionCreateError
@@ -3,10 +3,9 @@ package cassandra
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
-	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -15,10 +14,17 @@ import (
 
 func resourceCassandraRole() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceRoleCreate,
-		ReadContext:   resourceRoleRead,
-		UpdateContext: resourceRoleUpdate,
-		DeleteContext: resourceRoleDelete,
+		CreateContext: withAuditWarnings(resourceRoleCreate),
+		ReadContext:   withAuditWarnings(resourceRoleRead),
+		UpdateContext: withAuditWarnings(resourceRoleUpdate),
+		DeleteContext: withAuditWarnings(resourceRoleDelete),
+		CustomizeDiff: resourceRoleCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -52,11 +58,34 @@ func resourceCassandraRole() *schema.Resource {
 				Sensitive:    true,
 				ValidateFunc: validation.StringLenBetween(20, 512),
 			},
+			"consistency": resourceConsistencySchema(),
 		},
 	}
 }
 
-func readRole(session *gocql.Session, name string) (string, bool, bool, string, error) {
+// resourceRoleCustomizeDiff rejects roles at plan time when the flavor manages them outside of CQL or the cluster predates roles
+func resourceRoleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if flavor := meta.(*Client).knownFlavor(); flavor != nil {
+		if err := flavor.supportsType("cassandra_role"); err != nil {
+			return err
+		}
+	}
+
+	return meta.(*Client).requireFeature(ctx, featureRoles)
+}
+
+func readRole(ctx context.Context, client *Client, name string) (string, bool, bool, string, error) {
+	session, err := client.Session(ctx)
+
+	if err != nil {
+		return "", false, false, "", err
+	}
+
+	flavor, err := client.Flavor(ctx)
+
+	if err != nil {
+		return "", false, false, "", err
+	}
 
 	var (
 		role        string
@@ -65,14 +94,29 @@ func readRole(session *gocql.Session, name string) (string, bool, bool, string,
 		saltedHash  string
 	)
 
-	iter := session.Query(`select role, can_login, is_superuser, salted_hash from system_auth.roles where role = ?`, name).Iter()
+	for _, table := range flavor.rolesTables {
+		iter := client.query(ctx, session, fmt.Sprintf(`select role, can_login, is_superuser, salted_hash from %s where role = ?`, table), name).Iter()
+
+		tflog.Trace(ctx, "Read role", map[string]interface{}{
+			"table": table,
+			"rows":  iter.NumRows(),
+		})
+
+		found := iter.Scan(&role, &canLogin, &isSuperUser, &saltedHash)
+
+		if err := iter.Close(); err != nil {
+			if isUnconfiguredTable(err) {
+				continue
+			}
 
-	defer iter.Close()
+			return "", false, false, "", err
+		}
 
-	log.Printf("read role query returned %d", iter.NumRows())
+		if found {
+			return role, canLogin, isSuperUser, saltedHash, nil
+		}
 
-	for iter.Scan(&role, &canLogin, &isSuperUser, &saltedHash) {
-		return role, canLogin, isSuperUser, saltedHash, nil
+		break
 	}
 
 	return "", false, false, "", fmt.Errorf("cannot read role with name %s", name)
@@ -85,24 +129,33 @@ func resourceRoleCreateOrUpdate(ctx context.Context, d *schema.ResourceData, met
 	password := d.Get("password").(string)
 	var diags diag.Diagnostics
 
-	cluster := meta.(*gocql.ClusterConfig)
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
+	query := fmt.Sprintf(`%s ROLE '%s' WITH PASSWORD = '%s' AND LOGIN = %v AND SUPERUSER = %v`, boolToAction[createRole], name, password, login, superUser)
+
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		d.SetId(name)
+
+		return diags
+	}
 
-	log.Printf("Getting a session took %s", elapsed)
+	session, sessionCreateError := meta.(*Client).Session(ctx)
 
 	if sessionCreateError != nil {
 		return diag.FromErr(sessionCreateError)
 	}
 
-	defer session.Close()
+	start := time.Now()
 
-	createErr := session.Query(fmt.Sprintf(`%s ROLE '%s' WITH PASSWORD = '%s' AND LOGIN = %v AND SUPERUSER = %v`, boolToAction[createRole], name, password, login, superUser)).Exec()
+	createErr := meta.(*Client).query(ctx, session, query).Exec()
 	if createErr != nil {
 		return diag.FromErr(createErr)
 	}
 
+	diags = append(diags, meta.(*Client).awaitSchemaAgreement(ctx, session, start)...)
+
 	d.SetId(name)
 	d.Set("name", name)
 	d.Set("super_user", superUser)
@@ -115,6 +168,9 @@ func resourceRoleCreateOrUpdate(ctx context.Context, d *schema.ResourceData, met
 }
 
 func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ctx = withResource(ctx, "cassandra_role", d.Get("name").(string), operationCreate)
+	ctx = withConsistency(ctx, d)
+
 	return resourceRoleCreateOrUpdate(ctx, d, meta, true)
 }
 
@@ -123,19 +179,15 @@ func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	password := d.Get("password").(string)
 	var diags diag.Diagnostics
 
-	cluster := meta.(*gocql.ClusterConfig)
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
-
-	log.Printf("Getting a session took %s", elapsed)
-
-	if sessionCreateError != nil {
-		return diag.FromErr(sessionCreateError)
+	if meta.(*Client).offline() {
+		// nothing is read back, the state keeps the configuration
+		return diags
 	}
 
-	defer session.Close()
-	_name, login, superUser, saltedHash, readRoleErr := readRole(session, name)
+	ctx = withResource(ctx, "cassandra_role", name, operationRead)
+	ctx = withConsistency(ctx, d)
+
+	_name, login, superUser, saltedHash, readRoleErr := readRole(ctx, meta.(*Client), name)
 
 	if readRoleErr != nil {
 		return diag.FromErr(readRoleErr)
@@ -162,27 +214,44 @@ func resourceRoleDelete(ctx context.Context, d *schema.ResourceData, meta interf
 	name := d.Get("name").(string)
 	var diags diag.Diagnostics
 
-	cluster := meta.(*gocql.ClusterConfig)
-	start := time.Now()
-	session, sessionCreateError := cluster.CreateSession()
-	elapsed := time.Since(start)
+	ctx = withResource(ctx, "cassandra_role", name, operationDelete)
+	ctx = withConsistency(ctx, d)
 
-	log.Printf("Getting a session took %s", elapsed)
+	query := fmt.Sprintf(`DROP ROLE '%s'`, name)
+
+	if meta.(*Client).renderOnly() {
+		if err := meta.(*Client).render(ctx, query); err != nil {
+			return diag.FromErr(err)
+		}
+
+		return diags
+	}
+
+	session, sessionCreateError := meta.(*Client).Session(ctx)
 
 	if sessionCreateError != nil {
 		return diag.FromErr(sessionCreateError)
 	}
 
-	defer session.Close()
+	start := time.Now()
 
-	err := session.Query(fmt.Sprintf(`DROP ROLE '%s'`, name)).Exec()
+	err := meta.(*Client).query(ctx, session, query).Exec()
 	if err != nil {
-		diag.FromErr(err)
+		return diag.FromErr(err)
 	}
 
+	diags = append(diags, meta.(*Client).awaitSchemaAgreement(ctx, session, start)...)
+
 	return diags
 }
 
 func resourceRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ctx = withResource(ctx, "cassandra_role", d.Get("name").(string), operationUpdate)
+	ctx = withConsistency(ctx, d)
+
+	if onlyConsistencyChanged(d) {
+		return nil
+	}
+
 	return resourceRoleCreateOrUpdate(ctx, d, meta, false)
 }
@@ -1,11 +1,11 @@
 package cassandra
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"testing"
 
-	"github.com/gocql/gocql"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -56,15 +56,12 @@ resource "cassandra_role" "user" {
 }
 
 func testAccCassandraRoleDestroy(s *terraform.State) error {
-	cluster := testAccProvider.Meta().(*gocql.ClusterConfig)
-	session, sessionCreateError := cluster.CreateSession()
+	_, sessionCreateError := testAccProvider.Meta().(*Client).Session(context.Background())
 
 	if sessionCreateError != nil {
 		return sessionCreateError
 	}
 
-	defer session.Close()
-
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "cassandra_role" {
 			continue
@@ -72,7 +69,7 @@ func testAccCassandraRoleDestroy(s *terraform.State) error {
 
 		name := rs.Primary.Attributes["name"]
 
-		_, _, _, _, err := readRole(session, name)
+		_, _, _, _, err := readRole(context.Background(), testAccProvider.Meta().(*Client), name)
 
 		if err != nil {
 			return nil
@@ -95,15 +92,13 @@ func testAccCassandraRoleExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		cluster := testAccProvider.Meta().(*gocql.ClusterConfig)
-
-		session, sessionCreateError := cluster.CreateSession()
+		_, sessionCreateError := testAccProvider.Meta().(*Client).Session(context.Background())
 
 		if sessionCreateError != nil {
 			return sessionCreateError
 		}
 
-		_, _, _, _, err := readRole(session, rs.Primary.ID)
+		_, _, _, _, err := readRole(context.Background(), testAccProvider.Meta().(*Client), rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -0,0 +1,165 @@
+package cassandra
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4Service    = "cassandra"
+	sigV4DateFormat = "2006-01-02T15:04:05.000Z"
+	sigV4Initial    = "SigV4\x00\x00"
+	sigV4NonceKey   = "nonce="
+)
+
+// sigV4Credentials are the AWS credentials used to sign authentication challenges
+type sigV4Credentials struct {
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// sigV4Authenticator implements gocql.Authenticator with the SigV4 mechanism of Amazon Keyspaces,
+// the server answers the initial response with a nonce which is signed with the credentials
+type sigV4Authenticator struct {
+	credentials sigV4Credentials
+	now         func() time.Time
+}
+
+func (a *sigV4Authenticator) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	return []byte(sigV4Initial), &sigV4Signer{a}, nil
+}
+
+func (a *sigV4Authenticator) Success(data []byte) error {
+	return nil
+}
+
+// sigV4Signer answers the nonce challenge sent after the initial response
+type sigV4Signer struct {
+	authenticator *sigV4Authenticator
+}
+
+func (s *sigV4Signer) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	nonce, err := extractNonce(req)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now
+
+	if s.authenticator.now != nil {
+		now = s.authenticator.now
+	}
+
+	return []byte(signNonce(s.authenticator.credentials, nonce, now().UTC())), nil, nil
+}
+
+func (s *sigV4Signer) Success(data []byte) error {
+	return nil
+}
+
+// newSigV4Authenticator returns an authenticator for the credentials, missing values are read from the AWS environment variables
+func newSigV4Authenticator(credentials sigV4Credentials) (*sigV4Authenticator, error) {
+	if credentials.Region == "" {
+		credentials.Region = firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	}
+
+	if credentials.AccessKey == "" && credentials.SecretKey == "" {
+		credentials.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		credentials.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+
+		if credentials.SessionToken == "" {
+			credentials.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+
+	if credentials.Region == "" {
+		return nil, fmt.Errorf("region must be set, either in the sigv4 block or in the AWS_REGION environment variable")
+	}
+
+	if credentials.AccessKey == "" || credentials.SecretKey == "" {
+		return nil, fmt.Errorf("access_key and secret_key must be set, either in the sigv4 block or in the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	}
+
+	return &sigV4Authenticator{credentials: credentials}, nil
+}
+
+// extractNonce returns the nonce of a challenge such as nonce=0123abcd
+func extractNonce(challenge []byte) (string, error) {
+	index := bytes.Index(challenge, []byte(sigV4NonceKey))
+
+	if index < 0 {
+		return "", fmt.Errorf("SigV4 challenge does not contain a nonce")
+	}
+
+	nonce := challenge[index+len(sigV4NonceKey):]
+
+	if end := bytes.IndexByte(nonce, ','); end >= 0 {
+		nonce = nonce[:end]
+	}
+
+	return string(nonce), nil
+}
+
+// signNonce returns the response to a nonce challenge, a signed PUT /authenticate request carrying the nonce hash
+func signNonce(credentials sigV4Credentials, nonce string, t time.Time) string {
+	date := t.Format(sigV4DateFormat)
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", t.Format("20060102"), credentials.Region, sigV4Service)
+
+	query := fmt.Sprintf("X-Amz-Algorithm=%s&X-Amz-Credential=%s%%2F%s&X-Amz-Date=%s&X-Amz-Expires=900", sigV4Algorithm, credentials.AccessKey, url.QueryEscape(scope), url.QueryEscape(date))
+	canonicalRequest := fmt.Sprintf("PUT\n/authenticate\n%s\nhost:%s\n\nhost\n%s", query, sigV4Service, sha256Hex([]byte(nonce)))
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s", sigV4Algorithm, date, scope, sha256Hex([]byte(canonicalRequest)))
+
+	signature := hex.EncodeToString(hmacSHA256(sigV4SigningKey(credentials.SecretKey, t, credentials.Region, sigV4Service), stringToSign))
+
+	response := fmt.Sprintf("signature=%s,access_key=%s,amzdate=%s", signature, credentials.AccessKey, date)
+
+	if credentials.SessionToken != "" {
+		response += ",session_token=" + credentials.SessionToken
+	}
+
+	return response
+}
+
+// sigV4SigningKey derives the signing key of the day, region and service from the secret key
+func sigV4SigningKey(secretKey string, t time.Time, region string, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), t.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
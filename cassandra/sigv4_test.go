@@ -0,0 +1,360 @@
+package cassandra
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const (
+	testFrameHeaderLength   = 9
+	testOpcodeError         = 0x00
+	testOpcodeStartup       = 0x01
+	testOpcodeAuthenticate  = 0x03
+	testOpcodeAuthChallenge = 0x0E
+	testOpcodeAuthResponse  = 0x0F
+	testOpcodeAuthSuccess   = 0x10
+	testNonce               = "91703fdc2ef562e19fbdab0f58e42fe5"
+)
+
+var (
+	testSigV4Credentials = sigV4Credentials{
+		Region:       "us-west-2",
+		AccessKey:    "AKIDEXAMPLE",
+		SecretKey:    "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		SessionToken: "token",
+	}
+)
+
+func TestSigV4_signingKey(t *testing.T) {
+	// example of the AWS Signature Version 4 documentation
+	key := sigV4SigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", time.Date(2012, 2, 15, 0, 0, 0, 0, time.UTC), "us-east-1", "iam")
+
+	if hex.EncodeToString(key) != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Fatalf("unexpected signing key %x", key)
+	}
+}
+
+func TestSigV4_extractNonce(t *testing.T) {
+	for challenge, expected := range map[string]string{
+		"nonce=" + testNonce:              testNonce,
+		"nonce=" + testNonce + ",other=1": testNonce,
+	} {
+		nonce, err := extractNonce([]byte(challenge))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nonce != expected {
+			t.Fatalf("expected nonce %s, got %s", expected, nonce)
+		}
+	}
+
+	if _, err := extractNonce([]byte("salt=1")); err == nil {
+		t.Fatal("expected challenge without nonce to fail")
+	}
+}
+
+func TestSigV4_authenticateAgainstStubServer(t *testing.T) {
+	address := testSigV4Server(t, testSigV4Credentials)
+
+	authenticator := &sigV4Authenticator{credentials: testSigV4Credentials}
+
+	if err := testAuthenticate(address, authenticator); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongSecret := testSigV4Credentials
+	wrongSecret.SecretKey = "wrong"
+
+	if err := testAuthenticate(address, &sigV4Authenticator{credentials: wrongSecret}); err == nil || !strings.Contains(err.Error(), "signature does not match") {
+		t.Fatalf("expected authentication with a wrong secret to fail, got %v", err)
+	}
+}
+
+func TestSigV4_configureFromEnvironment(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":   "cassandra.eu-west-1.amazonaws.com",
+		"flavor": "aws_keyspaces",
+	})
+	p := Provider()
+	v := p.Validate(rc)
+	if v.HasError() {
+		t.Fatalf("Error during parsing: %v", v)
+	}
+	diags := p.Configure(context.Background(), rc)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	defer CloseClients()
+
+	authenticator, ok := p.Meta().(*Client).Cluster().Authenticator.(*sigV4Authenticator)
+	if !ok {
+		t.Fatalf("expected SigV4 authenticator, got %T", p.Meta().(*Client).Cluster().Authenticator)
+	}
+
+	if authenticator.credentials.Region != "eu-west-1" || authenticator.credentials.AccessKey != "AKIDEXAMPLE" {
+		t.Fatalf("unexpected credentials %+v", authenticator.credentials)
+	}
+}
+
+func TestSigV4_configureWithoutCredentials(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	rc := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":   "cassandra.eu-west-1.amazonaws.com",
+		"flavor": "aws_keyspaces",
+		"sigv4": []interface{}{
+			map[string]interface{}{
+				"region": "eu-west-1",
+			},
+		},
+	})
+	diags := Provider().Configure(context.Background(), rc)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "access_key and secret_key must be set") {
+		t.Fatalf("unexpected diagnostics %+v", diags)
+	}
+}
+
+func TestSigV4_unsupportedResources(t *testing.T) {
+	f := flavors[flavorAWSKeyspaces]
+
+	for _, typeName := range []string{"cassandra_role", "cassandra_grant"} {
+		if err := f.supportsType(typeName); err == nil {
+			t.Fatalf("expected %s to be unsupported", typeName)
+		}
+	}
+
+	if err := f.supportsType("cassandra_keyspace"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.supportsReplicationStrategy("NetworkTopologyStrategy"); err == nil {
+		t.Fatal("expected NetworkTopologyStrategy to be unsupported")
+	}
+
+	if err := f.supportsReplicationStrategy("SingleRegionStrategy"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testSigV4Server starts a server requiring SigV4 authentication, the signature is checked against the credentials
+func testSigV4Server(t *testing.T, credentials sigV4Credentials) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				if opcode, _, err := testReadFrame(conn); err != nil || opcode != testOpcodeStartup {
+					return
+				}
+
+				testWriteFrame(conn, testOpcodeAuthenticate, testStringBody("com.amazonaws.cassandra.DefaultAuthenticator"))
+
+				if _, body, err := testReadFrame(conn); err != nil || string(testBytesBody(body)) != sigV4Initial {
+					testWriteFrame(conn, testOpcodeError, testErrorBody("expected SigV4 initial response"))
+					return
+				}
+
+				testWriteFrame(conn, testOpcodeAuthChallenge, testBytesFrameBody([]byte("nonce="+testNonce)))
+
+				_, body, err := testReadFrame(conn)
+				if err != nil {
+					return
+				}
+
+				fields := map[string]string{}
+				for _, field := range strings.Split(string(testBytesBody(body)), ",") {
+					if parts := strings.SplitN(field, "=", 2); len(parts) == 2 {
+						fields[parts[0]] = parts[1]
+					}
+				}
+
+				date, err := time.Parse(sigV4DateFormat, fields["amzdate"])
+				if err != nil || fields["access_key"] != credentials.AccessKey || fields["session_token"] != credentials.SessionToken {
+					testWriteFrame(conn, testOpcodeError, testErrorBody("invalid SigV4 response"))
+					return
+				}
+
+				if signNonce(credentials, testNonce, date) != string(testBytesBody(body)) {
+					testWriteFrame(conn, testOpcodeError, testErrorBody("signature does not match"))
+					return
+				}
+
+				testWriteFrame(conn, testOpcodeAuthSuccess, testBytesFrameBody(nil))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// testAuthenticate runs the STARTUP and authentication exchange of the native protocol with the authenticator
+func testAuthenticate(address string, authenticator gocql.Authenticator) error {
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := conn.Write(testStartupFrame(4, "3.0.0")); err != nil {
+		return err
+	}
+
+	opcode, body, err := testReadFrame(conn)
+	if err != nil {
+		return err
+	}
+
+	if opcode != testOpcodeAuthenticate {
+		return fmt.Errorf("expected AUTHENTICATE, got opcode %d", opcode)
+	}
+
+	response, challenger, err := authenticator.Challenge(body[2:])
+	if err != nil {
+		return err
+	}
+
+	for {
+		testWriteFrame(conn, testOpcodeAuthResponse, testBytesFrameBody(response))
+
+		opcode, body, err := testReadFrame(conn)
+		if err != nil {
+			return err
+		}
+
+		switch opcode {
+		case testOpcodeAuthSuccess:
+			return nil
+		case testOpcodeAuthChallenge:
+			if challenger == nil {
+				return fmt.Errorf("unexpected challenge")
+			}
+
+			response, challenger, err = challenger.Challenge(testBytesBody(body))
+			if err != nil {
+				return err
+			}
+		case testOpcodeError:
+			return fmt.Errorf("authentication failed: %s", testErrorMessage(body))
+		default:
+			return fmt.Errorf("unexpected opcode %d", opcode)
+		}
+	}
+}
+
+func testStartupFrame(version int, cqlVersion string) []byte {
+	var body bytes.Buffer
+
+	writeShort := func(v int) {
+		binary.Write(&body, binary.BigEndian, uint16(v))
+	}
+
+	writeString := func(s string) {
+		writeShort(len(s))
+		body.WriteString(s)
+	}
+
+	writeShort(1)
+	writeString("CQL_VERSION")
+	writeString(cqlVersion)
+
+	frame := make([]byte, testFrameHeaderLength, testFrameHeaderLength+body.Len())
+	frame[0] = byte(version)
+	frame[4] = testOpcodeStartup
+	binary.BigEndian.PutUint32(frame[5:], uint32(body.Len()))
+
+	return append(frame, body.Bytes()...)
+}
+
+func testErrorMessage(body []byte) string {
+	if len(body) < 6 {
+		return "unknown error"
+	}
+
+	length := int(binary.BigEndian.Uint16(body[4:]))
+
+	if len(body) < 6+length {
+		return "unknown error"
+	}
+
+	return string(body[6 : 6+length])
+}
+
+func testReadFrame(conn net.Conn) (byte, []byte, error) {
+	header := make([]byte, testFrameHeaderLength)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, nil, err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(header[5:]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header[4], body, nil
+}
+
+func testWriteFrame(conn net.Conn, opcode byte, body []byte) {
+	frame := make([]byte, testFrameHeaderLength, testFrameHeaderLength+len(body))
+	frame[0] = 4 | responseVersionFlag
+	frame[4] = opcode
+	binary.BigEndian.PutUint32(frame[5:], uint32(len(body)))
+	conn.Write(append(frame, body...))
+}
+
+func testStringBody(s string) []byte {
+	body := make([]byte, 2, 2+len(s))
+	binary.BigEndian.PutUint16(body, uint16(len(s)))
+	return append(body, s...)
+}
+
+func testBytesFrameBody(b []byte) []byte {
+	body := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(body, uint32(len(b)))
+	return append(body, b...)
+}
+
+func testBytesBody(body []byte) []byte {
+	if len(body) < 4 {
+		return nil
+	}
+
+	return body[4:]
+}
+
+func testErrorBody(message string) []byte {
+	body := make([]byte, 4)
+	binary.BigEndian.PutUint32(body, 0x0100)
+	return append(body, testStringBody(message)...)
+}
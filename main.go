@@ -1,10 +1,44 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
+
 	"github.com/bartoszj/terraform-provider-cassandra/cassandra"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 )
 
+const providerAddr = "registry.terraform.io/bartoszj/cassandra"
+
 func main() {
-	plugin.Serve(&plugin.ServeOpts{ProviderFunc: cassandra.Provider})
+	var debugMode bool
+
+	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	// log.Fatal exits without running deferred calls, so the clients are closed by run
+	if err := run(debugMode); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// run serves the provider until Terraform stops it, the sessions and the audit log are closed when it returns
+func run(debugMode bool) error {
+	defer cassandra.CloseClients()
+
+	providerServer, err := cassandra.NewProviderServer(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	var serveOpts []tf5server.ServeOpt
+
+	if debugMode {
+		// prints the TF_REATTACH_PROVIDERS value Terraform needs to use the running provider
+		serveOpts = append(serveOpts, tf5server.WithManagedDebug())
+	}
+
+	return tf5server.Serve(providerAddr, providerServer, serveOpts...)
 }